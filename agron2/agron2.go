@@ -3,22 +3,25 @@ package agron2
 import (
 	"crypto/subtle"
 	"encoding/hex"
-	"errors"
-	"fmt"
 	"strconv"
 	"strings"
 
-	"golang.org/x/crypto/argon2"
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/phc"
 )
 
 type Argon2Context struct {
-	Pwd       string // password string
-	Salt      string // salt string
-	Secretlen uint32 // key length
-	Mcost     uint32 // amount of memory requested (KB)
-	Threads   uint8  // maximum number of threads or lanes
-	Tcost     uint32 // number of passes
-	Version   int    // version number
+	Pwd       string         // password string
+	Salt      string         // salt string
+	Secret    []byte         // secret value (K), optional
+	AD        []byte         // associated data (X), optional
+	Secretlen uint32         // key length
+	Mcost     uint32         // amount of memory requested (KB)
+	Threads   uint8          // maximum number of threads or lanes
+	Tcost     uint32         // number of passes
+	Version   int            // version number
+	Encoding  Argon2Encoding // salt and hash encoding of the encoded string
+	KeyID     string         // id of the pepper in Secret, recorded as keyid=, optional
 }
 
 type Argon2Type int
@@ -29,8 +32,21 @@ const (
 	Argon2Id
 )
 
+type Argon2Encoding int
+
+const (
+	Argon2EncodingBase64 Argon2Encoding = iota // unpadded standard base64, as the PHC string format
+	Argon2EncodingHex                          // legacy hex encoding
+)
+
 const Uint32Max = 4294967295
 
+const (
+	Argon2Version10     = 0x10 // Version of the algorithm
+	Argon2Version13     = 0x13
+	Argon2VersionNumber = Argon2Version13
+)
+
 func Argon2Min(a, b uint64) uint64 {
 	if a < b {
 		return a
@@ -45,6 +61,8 @@ const (
 	Argon2MaxSaltLength uint32 = 0xFFFFFFFF
 	Argon2MinSecret     uint32 = 0 // Minimum and maximum key length in bytes
 	Argon2MaxSecret     uint32 = 0xFFFFFFFF
+	Argon2MinAdLength   uint32 = 0 // Minimum and maximum associated data length in bytes
+	Argon2MaxAdLength   uint32 = 0xFFFFFFFF
 	Argon2SyncPoints    uint32 = 4
 	Argon2MinMemory     uint32 = 2 * Argon2SyncPoints // 2 blocks per slice
 	Argon2MinThreads    uint32 = 1
@@ -59,147 +77,180 @@ var (
 	Argon2MaxMemory     uint64 = Argon2Min(uint64(0xFFFFFFFF), uint64(1)<<Argon2MaxMemoryBits)
 )
 
+// Argon2 error codes, those of kdf.
 const (
-	Argon2Ok = iota
-	Argon2PwdTooShort
-	Argon2PwdTooLong
-	Argon2SaltTooShort
-	Argon2SaltTooLong
-	Argon2SecretTooShort
-	Argon2SecretTooLong
-	Argon2TimeTooSmall
-	Argon2TimeTooLarge
-	Argon2MemoryTooLittle
-	Argon2MemoryTooMuch
-	Argon2PwdPtrMismatch
-	Argon2SaltPtrMismatch
-	Argon2SecretPtrMismatch
-	Argon2IncorrectType
-	Argon2ThreadsTooFew
-	Argon2ThreadsTooMany
-	Argon2DecodingFail
-	Argon2VerifyMismatch
+	Argon2Ok                = kdf.Ok
+	Argon2PwdTooShort       = kdf.PwdTooShort
+	Argon2PwdTooLong        = kdf.PwdTooLong
+	Argon2SaltTooShort      = kdf.SaltTooShort
+	Argon2SaltTooLong       = kdf.SaltTooLong
+	Argon2SecretTooShort    = kdf.SecretTooShort
+	Argon2SecretTooLong     = kdf.SecretTooLong
+	Argon2TimeTooSmall      = kdf.TimeTooSmall
+	Argon2TimeTooLarge      = kdf.TimeTooLarge
+	Argon2MemoryTooLittle   = kdf.MemoryTooLittle
+	Argon2MemoryTooMuch     = kdf.MemoryTooMuch
+	Argon2PwdPtrMismatch    = kdf.PwdPtrMismatch
+	Argon2SaltPtrMismatch   = kdf.SaltPtrMismatch
+	Argon2SecretPtrMismatch = kdf.SecretPtrMismatch
+	Argon2IncorrectType     = kdf.IncorrectType
+	Argon2ThreadsTooFew     = kdf.ThreadsTooFew
+	Argon2ThreadsTooMany    = kdf.ThreadsTooMany
+	Argon2DecodingFail      = kdf.DecodingFail
+	Argon2VerifyMismatch    = kdf.VerifyMismatch
+	Argon2AdTooShort        = kdf.AdTooShort
+	Argon2AdTooLong         = kdf.AdTooLong
+	Argon2IncorrectVersion  = kdf.IncorrectVersion
+	Argon2SaltLowEntropy    = kdf.SaltLowEntropy
+	Argon2TooWeak           = kdf.TooWeak
+	Argon2UnknownKey        = kdf.UnknownKey
 )
 
 func Argon2ErrorMessage(errorCode int) string {
-	switch errorCode {
-	case Argon2Ok:
-		return "OK"
-	case Argon2PwdTooShort:
-		return "Password is too short"
-	case Argon2PwdTooLong:
-		return "Password is too long"
-	case Argon2SaltTooShort:
-		return "Salt is too short"
-	case Argon2SaltTooLong:
-		return "Salt is too long"
-	case Argon2SecretTooShort:
-		return "Secret is too short"
-	case Argon2SecretTooLong:
-		return "Secret is too long"
-	case Argon2TimeTooSmall:
-		return "Time cost is too small"
-	case Argon2TimeTooLarge:
-		return "Time cost is too large"
-	case Argon2MemoryTooLittle:
-		return "Memory cost is too small"
-	case Argon2MemoryTooMuch:
-		return "Memory cost is too large"
-	case Argon2PwdPtrMismatch:
-		return "Password pointer is NULL, but password length is not 0"
-	case Argon2SaltPtrMismatch:
-		return "Salt pointer is NULL, but salt length is not 0"
-	case Argon2SecretPtrMismatch:
-		return "Secret pointer is NULL, but secret length is not 0"
-	case Argon2IncorrectType:
-		return "There is no such version of Argon2"
-	case Argon2ThreadsTooFew:
-		return "Not enough threads"
-	case Argon2ThreadsTooMany:
-		return "Too many threads"
-	case Argon2DecodingFail:
-		return "Decoding failed"
-	case Argon2VerifyMismatch:
-		return "The password does not match the supplied hash"
-	default:
-		return "Unknown error code"
-	}
+	return kdf.ErrorMessage(errorCode)
 }
 
 func ValidateInputs(context Argon2Context) int {
-	// Validate password (required param)
-	pwdLen := uint32(len(context.Pwd))
-	if 0 == pwdLen {
-		return Argon2PwdPtrMismatch
+	if errs := validateInputs(context, len(context.Pwd), false); len(errs) != 0 {
+		return errs[0].Code
 	}
 
-	if Argon2MinPwdLength > pwdLen {
-		return Argon2PwdTooShort
-	}
+	return Argon2Ok
+}
 
-	if Argon2MaxPwdLength < pwdLen {
-		return Argon2PwdTooLong
+// ValidateAll is like ValidateInputs but reports every violation at once as
+// Errors, or nil when the context is valid.
+func ValidateAll(context Argon2Context) error {
+	if errs := validateInputs(context, len(context.Pwd), true); len(errs) != 0 {
+		return errs
 	}
 
-	// Validate salt (required param)
-	saltLen := uint32(len(context.Salt))
-	if 0 == saltLen {
-		return Argon2SaltPtrMismatch
+	return nil
+}
+
+func validateInputs(context Argon2Context, pwdLength int, all bool) Errors {
+	var errs Errors
+	fail := func(code int, field string) bool {
+		errs = append(errs, newError(code, field))
+		return !all
 	}
 
-	if Argon2MinSaltLength > saltLen {
-		return Argon2SaltTooShort
+	// Validate password (required param)
+	pwdLen := uint32(pwdLength)
+	if 0 == pwdLen {
+		if fail(Argon2PwdPtrMismatch, "Pwd") {
+			return errs
+		}
+	} else if Argon2MinPwdLength > pwdLen {
+		if fail(Argon2PwdTooShort, "Pwd") {
+			return errs
+		}
+	} else if int64(pwdLength) > int64(Argon2MaxPwdLength) {
+		if fail(Argon2PwdTooLong, "Pwd") {
+			return errs
+		}
 	}
 
-	if Argon2MaxSaltLength < saltLen {
-		return Argon2SaltTooLong
+	// Validate salt (required param)
+	saltLen := uint32(len(context.Salt))
+	if 0 == saltLen {
+		if fail(Argon2SaltPtrMismatch, "Salt") {
+			return errs
+		}
+	} else if Argon2MinSaltLength > saltLen {
+		if fail(Argon2SaltTooShort, "Salt") {
+			return errs
+		}
+	} else if int64(len(context.Salt)) > int64(Argon2MaxSaltLength) {
+		if fail(Argon2SaltTooLong, "Salt") {
+			return errs
+		}
 	}
 
 	// Validate secret (optional param)
 	if 0 == context.Secretlen {
-		return Argon2SecretPtrMismatch
-	} else {
-		if Argon2MinSecret > context.Secretlen {
-			return Argon2SecretTooShort
+		if fail(Argon2SecretPtrMismatch, "Secretlen") {
+			return errs
 		}
-		if Argon2MaxSecret < context.Secretlen {
-			return Argon2SecretTooLong
+	} else if Argon2MinSecret > context.Secretlen {
+		if fail(Argon2SecretTooShort, "Secretlen") {
+			return errs
+		}
+	} else if Argon2MaxSecret < context.Secretlen {
+		if fail(Argon2SecretTooLong, "Secretlen") {
+			return errs
 		}
 	}
 
-	// Validate memory cost
-	if Argon2MinMemory > context.Mcost {
-		return Argon2MemoryTooLittle
+	// Validate secret value (optional param)
+	if Argon2MinSecret > uint32(len(context.Secret)) {
+		if fail(Argon2SecretTooShort, "Secret") {
+			return errs
+		}
+	} else if int64(len(context.Secret)) > int64(Argon2MaxSecret) {
+		if fail(Argon2SecretTooLong, "Secret") {
+			return errs
+		}
 	}
 
-	if Argon2MaxMemory < uint64(context.Mcost) {
-		return Argon2MemoryTooMuch
+	// Validate associated data (optional param)
+	if Argon2MinAdLength > uint32(len(context.AD)) {
+		if fail(Argon2AdTooShort, "AD") {
+			return errs
+		}
+	} else if int64(len(context.AD)) > int64(Argon2MaxAdLength) {
+		if fail(Argon2AdTooLong, "AD") {
+			return errs
+		}
 	}
 
-	//if context.Mcost < 8*context.Lanes {
-	if context.Mcost < 8*uint32(context.Threads) {
-		return Argon2MemoryTooLittle
+	// Validate memory cost
+	if Argon2MinMemory > context.Mcost {
+		if fail(Argon2MemoryTooLittle, "Mcost") {
+			return errs
+		}
+	} else if Argon2MaxMemory < uint64(context.Mcost) {
+		if fail(Argon2MemoryTooMuch, "Mcost") {
+			return errs
+		}
+	} else if context.Mcost < 8*uint32(context.Threads) {
+		if fail(Argon2MemoryTooLittle, "Mcost") {
+			return errs
+		}
 	}
 
 	// Validate time cost
 	if Argon2MinTime > context.Tcost {
-		return Argon2TimeTooSmall
-	}
-
-	if Argon2MaxTime < context.Tcost {
-		return Argon2TimeTooLarge
+		if fail(Argon2TimeTooSmall, "Tcost") {
+			return errs
+		}
+	} else if Argon2MaxTime < context.Tcost {
+		if fail(Argon2TimeTooLarge, "Tcost") {
+			return errs
+		}
 	}
 
 	// Validate threads
 	if Argon2MinThreads > uint32(context.Threads) {
-		return Argon2ThreadsTooFew
+		if fail(Argon2ThreadsTooFew, "Threads") {
+			return errs
+		}
+	} else if Argon2MaxThreads < uint32(context.Threads) {
+		if fail(Argon2ThreadsTooMany, "Threads") {
+			return errs
+		}
 	}
 
-	if Argon2MaxThreads < uint32(context.Threads) {
-		return Argon2ThreadsTooMany
+	// Validate version, zero means the latest version
+	switch context.Version {
+	case 0, Argon2Version10, Argon2Version13:
+	default:
+		if fail(Argon2IncorrectVersion, "Version") {
+			return errs
+		}
 	}
 
-	return Argon2Ok
+	return errs
 }
 
 func Argon2Type2String(types Argon2Type, uppercase bool) string {
@@ -224,27 +275,20 @@ func Argon2Type2String(types Argon2Type, uppercase bool) string {
 	return ""
 }
 
-func Argon2Ctx(context Argon2Context, types Argon2Type) (string, error) {
-	if ret := ValidateInputs(context); ret != Argon2Ok {
-		return "", errors.New(Argon2ErrorMessage(ret))
-	}
-
-	switch types {
-	case Argon2I, Argon2Id:
-	default:
-		return "", errors.New(Argon2ErrorMessage(Argon2IncorrectType))
+// Argon2String2Type parses the lowercase name of an Argon2 type, the
+// spelling of encoded strings, as returned by Argon2Type2String.
+func Argon2String2Type(str string) (Argon2Type, error) {
+	for _, types := range []Argon2Type{Argon2D, Argon2I, Argon2Id} {
+		if str == Argon2Type2String(types, false) {
+			return types, nil
+		}
 	}
 
-	var out strings.Builder
-	switch types {
-	case Argon2I:
-		out.Write(argon2.Key([]byte(context.Pwd), []byte(context.Salt), context.Tcost, context.Mcost, context.Threads, context.Secretlen))
-	case Argon2Id:
-		out.Write(argon2.IDKey([]byte(context.Pwd), []byte(context.Salt), context.Tcost, context.Mcost, context.Threads, context.Secretlen))
-	}
+	return 0, newError(Argon2IncorrectType, "")
+}
 
-	ret := out.String()
-	return ret, nil
+func Argon2Ctx(context Argon2Context, types Argon2Type) (string, error) {
+	return Argon2CtxContext(background, context, types)
 }
 
 func Argon2Compare(hash, pwd string) bool {
@@ -262,47 +306,108 @@ func Argon2VerifyCtx(context Argon2Context, hash string, types Argon2Type) error
 		return nil
 	}
 
-	return errors.New(Argon2ErrorMessage(Argon2VerifyMismatch))
+	return newError(Argon2VerifyMismatch, "")
 }
 
 func DecodeString(context Argon2Context, encoded string, types Argon2Type) (Argon2Context, string, error) {
-	vals := strings.Split(encoded, "$")
-	if len(vals) != 6 {
-		return Argon2Context{}, "", errors.New("v")
+	h, err := phc.Parse(encoded)
+	if err != nil {
+		return Argon2Context{}, "", decodingError("", err)
+	}
+
+	if h.ID != Argon2Type2String(types, false) {
+		return Argon2Context{}, "", newError(Argon2IncorrectType, "id")
+	}
+
+	// Hashes without the version segment were produced by version 1.0
+	version := Argon2Version10
+	if h.HasVersion {
+		version = h.Version
+	}
+	if version != Argon2Version10 && version != Argon2Version13 {
+		return Argon2Context{}, "", newError(Argon2IncorrectVersion, "v")
+	}
+	context.Version = version
+
+	var hasMcost, hasTcost, hasThreads bool
+	for _, p := range h.Params {
+		switch p.Name {
+		case "m":
+			n, err := phc.ParseUint(p.Value, 32)
+			if err != nil {
+				return Argon2Context{}, "", decodingError("m", err)
+			}
+			context.Mcost, hasMcost = uint32(n), true
+		case "t":
+			n, err := phc.ParseUint(p.Value, 32)
+			if err != nil {
+				return Argon2Context{}, "", decodingError("t", err)
+			}
+			context.Tcost, hasTcost = uint32(n), true
+		case "p":
+			n, err := phc.ParseUint(p.Value, 8)
+			if err != nil {
+				return Argon2Context{}, "", decodingError("p", err)
+			}
+			context.Threads, hasThreads = uint8(n), true
+		case "keyid":
+			keyID, err := phc.B64.DecodeString(p.Value)
+			if err != nil {
+				return Argon2Context{}, "", decodingError("keyid", err)
+			}
+			if len(keyID) > 8 {
+				return Argon2Context{}, "", newError(Argon2DecodingFail, "keyid")
+			}
+			context.KeyID = string(keyID)
+		case "data":
+			ad, err := phc.B64.DecodeString(p.Value)
+			if err != nil {
+				return Argon2Context{}, "", decodingError("data", err)
+			}
+			context.AD = ad
+		default:
+			return Argon2Context{}, "", newError(Argon2DecodingFail, p.Name)
+		}
 	}
-
-	if vals[1] != Argon2Type2String(types, false) {
-		return Argon2Context{}, "", errors.New(Argon2ErrorMessage(Argon2IncorrectType))
+	if !hasMcost {
+		return Argon2Context{}, "", newError(Argon2DecodingFail, "m")
+	}
+	if !hasTcost {
+		return Argon2Context{}, "", newError(Argon2DecodingFail, "t")
+	}
+	if !hasThreads {
+		return Argon2Context{}, "", newError(Argon2DecodingFail, "p")
 	}
 
-	var version int
-	_, err := fmt.Sscanf(vals[2], "v=%d", &version)
-	if err != nil {
-		return Argon2Context{}, "", errors.New("something wrong in argon 2 version")
+	if h.Salt == "" {
+		return Argon2Context{}, "", newError(Argon2DecodingFail, "salt")
 	}
-	if version != argon2.Version {
-		return Argon2Context{}, "", errors.New("something wrong in argon 2 version")
+	if h.Hash == "" {
+		return Argon2Context{}, "", newError(Argon2DecodingFail, "hash")
 	}
 
-	_, err = fmt.Sscanf(vals[3], "m=%d,t=%d,p=%d", &context.Mcost, &context.Tcost, &context.Threads)
-	if err != nil {
-		return Argon2Context{}, "", errors.New("something wrong in argon 2 memory, time, and threads")
+	// Rows stored before the PHC encoding used hex for both salt and hash
+	decode := phc.B64.DecodeString
+	context.Encoding = Argon2EncodingBase64
+	if isHex(h.Salt) && isHex(h.Hash) {
+		decode = hex.DecodeString
+		context.Encoding = Argon2EncodingHex
 	}
 
 	var sb strings.Builder
 
-	salt, err := hex.DecodeString(vals[4])
+	salt, err := decode(h.Salt)
 	if err != nil {
-		return Argon2Context{}, "", errors.New("something wrong in argon 2 salt")
+		return Argon2Context{}, "", decodingError("salt", err)
 	}
 
 	sb.Write(salt)
 	context.Salt = sb.String()
 	sb.Reset()
 
-	secret, err := hex.DecodeString(vals[5])
+	secret, err := decode(h.Hash)
 	if err != nil {
-		return Argon2Context{}, "", errors.New("something wrong in argon 2 secret")
+		return Argon2Context{}, "", decodingError("hash", err)
 	}
 	sb.Write(secret)
 	ret := sb.String()
@@ -311,91 +416,97 @@ func DecodeString(context Argon2Context, encoded string, types Argon2Type) (Argo
 	return context, ret, nil
 }
 
+func isHex(s string) bool {
+	if len(s)%2 != 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
 func EncodeString(ctx Argon2Context, types Argon2Type, secret string) string {
-	// Base64 encode the salt and hashed password.
-	b64Salt := hex.EncodeToString([]byte(ctx.Salt))
-	b64Hash := hex.EncodeToString([]byte(secret))
-	typeString := Argon2Type2String(types, false)
-
-	var out strings.Builder
-	out.WriteString("$")
-	out.WriteString(typeString)
-	out.WriteString("$v=")
-	out.WriteString(strconv.FormatUint(uint64(ctx.Version), 10))
-	out.WriteString("$m=")
-	out.WriteString(strconv.FormatUint(uint64(ctx.Mcost), 10))
-	out.WriteString(",t=")
-	out.WriteString(strconv.FormatUint(uint64(ctx.Tcost), 10))
-	out.WriteString(",p=")
-	out.WriteString(strconv.FormatUint(uint64(ctx.Threads), 10))
-	out.WriteString("$")
-	out.WriteString(b64Salt)
-	out.WriteString("$")
-	out.WriteString(b64Hash)
-
-	ret := out.String()
+	ret := encodeString(ctx, types, []byte(secret))
 	return ret
 }
 
-func Argon2Hash(password, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
-	switch types {
-	case Argon2I, Argon2Id:
-	default:
-		return "", errors.New(Argon2ErrorMessage(Argon2IncorrectType))
+func encodeString(ctx Argon2Context, types Argon2Type, secret []byte) string {
+	// Base64 encode the salt and hashed password.
+	b64Salt := phc.B64.EncodeToString([]byte(ctx.Salt))
+	b64Hash := phc.B64.EncodeToString(secret)
+	if ctx.Encoding == Argon2EncodingHex {
+		b64Salt = hex.EncodeToString([]byte(ctx.Salt))
+		b64Hash = hex.EncodeToString(secret)
 	}
-
-	ctx := Argon2Context{
-		Pwd:       password,
-		Salt:      salt,
-		Secretlen: keyLen,
-		Mcost:     memory,
-		Threads:   threads,
-		Tcost:     time,
-		Version:   version,
+	version := ctx.Version
+	if version == 0 {
+		version = Argon2VersionNumber
 	}
 
-	key, err := Argon2Ctx(ctx, types)
-	if err != nil {
-		return "", err
+	h := phc.Hash{
+		ID:         Argon2Type2String(types, false),
+		Version:    version,
+		HasVersion: true,
+		Params: []phc.Param{
+			{Name: "m", Value: strconv.FormatUint(uint64(ctx.Mcost), 10)},
+			{Name: "t", Value: strconv.FormatUint(uint64(ctx.Tcost), 10)},
+			{Name: "p", Value: strconv.FormatUint(uint64(ctx.Threads), 10)},
+		},
+		Salt: b64Salt,
+		Hash: b64Hash,
+	}
+	if ctx.KeyID != "" {
+		h.Params = append(h.Params, phc.Param{Name: "keyid", Value: phc.B64.EncodeToString([]byte(ctx.KeyID))})
+	}
+	if len(ctx.AD) != 0 {
+		h.Params = append(h.Params, phc.Param{Name: "data", Value: phc.B64.EncodeToString(ctx.AD)})
 	}
 
-	ret := EncodeString(ctx, types, key)
-	return ret, nil
+	ret := h.String()
+	return ret
 }
 
-func Argon2Verify(encoded, pwd string, types Argon2Type) error {
-	switch types {
-	case Argon2I, Argon2Id:
-	default:
-		return errors.New(Argon2ErrorMessage(Argon2IncorrectType))
-	}
+func Argon2Hash(password, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
+	return Argon2HashContext(background, password, salt, time, memory, threads, keyLen, version, types)
+}
 
-	var ctx Argon2Context
-	var encodedLen uint
+func Argon2Verify(encoded, pwd string, types Argon2Type) error {
+	return Argon2VerifyContext(background, encoded, pwd, types)
+}
 
-	if int64(len(pwd)) > int64(Argon2MaxPwdLength) {
-		return errors.New(Argon2ErrorMessage(Argon2PwdTooLong))
-	}
+// Argon2VerifyAny is like Argon2Verify but takes the type from the encoded
+// string. When allowed is not empty, types outside of it are rejected.
+func Argon2VerifyAny(encoded, pwd string, allowed ...Argon2Type) error {
+	return Verifier{}.VerifyAny(background, encoded, pwd, allowed...)
+}
 
-	encodedLen = uint(len(encoded))
-	if encodedLen == 0 {
-		return errors.New(Argon2ErrorMessage(Argon2DecodingFail))
-	}
-	if encodedLen > Uint32Max {
-		return errors.New(Argon2ErrorMessage(Argon2DecodingFail))
+// decodeType returns the type of encoded, which must be one of allowed unless
+// allowed is empty.
+func decodeType(encoded string, allowed []Argon2Type) (Argon2Type, error) {
+	h, err := phc.Parse(encoded)
+	if err != nil {
+		return 0, decodingError("", err)
 	}
 
-	ctx.Pwd = pwd
-
-	decodedContext, secret, err := DecodeString(ctx, encoded, types)
+	types, err := Argon2String2Type(h.ID)
 	if err != nil {
-		return err
+		return 0, newError(Argon2IncorrectType, "id")
 	}
 
-	decodedContext.Pwd = ctx.Pwd
-	if err = Argon2VerifyCtx(decodedContext, secret, types); err != nil {
-		return err
+	if len(allowed) == 0 {
+		return types, nil
+	}
+	for _, a := range allowed {
+		if a == types {
+			return types, nil
+		}
 	}
 
-	return nil
+	return 0, newError(Argon2IncorrectType, "id")
 }
@@ -3,9 +3,12 @@
 package agron2_test
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/fikryfahrezy/crypt/agron2"
+	"strings"
 	"testing"
 
 	"golang.org/x/crypto/argon2"
@@ -17,6 +20,10 @@ var testVectors = []struct {
 	threads      uint8
 	hash         string
 }{
+	{
+		mode: agron2.Argon2D, time: 1, memory: 64, threads: 1,
+		hash: "8727405fd07c32c78d64f547f24150d3f2e703a89f981a19",
+	},
 	{
 		mode: agron2.Argon2I, time: 1, memory: 64, threads: 1,
 		hash: "b9c401d1844a67d50eae3967dc28870b22e508092e861a37",
@@ -25,6 +32,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 1, memory: 64, threads: 1,
 		hash: "655ad15eac652dc59f7170a7332bf49b8469be1fdb9c28bb",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 1,
+		hash: "3be9ec79a69b75d3752acb59a1fbb8b295a46529c48fbb75",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 1,
 		hash: "8cf3d8f76a6617afe35fac48eb0b7433a9a670ca4a07ed64",
@@ -33,6 +44,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 2, memory: 64, threads: 1,
 		hash: "068d62b26455936aa6ebe60060b0a65870dbfa3ddf8d41f7",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 2,
+		hash: "68e2462c98b8bc6bb60ec68db418ae2c9ed24fc6748a40e9",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 2,
 		hash: "2089f3e78a799720f80af806553128f29b132cafe40d059f",
@@ -41,6 +56,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 2, memory: 64, threads: 2,
 		hash: "350ac37222f436ccb5c0972f1ebd3bf6b958bf2071841362",
 	},
+	{
+		mode: agron2.Argon2D, time: 3, memory: 256, threads: 2,
+		hash: "f4f0669218eaf3641f39cc97efb915721102f4b128211ef2",
+	},
 	{
 		mode: agron2.Argon2I, time: 3, memory: 256, threads: 2,
 		hash: "f5bbf5d4c3836af13193053155b73ec7476a6a2eb93fd5e6",
@@ -49,6 +68,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 3, memory: 256, threads: 2,
 		hash: "4668d30ac4187e6878eedeacf0fd83c5a0a30db2cc16ef0b",
 	},
+	{
+		mode: agron2.Argon2D, time: 4, memory: 4096, threads: 4,
+		hash: "935598181aa8dc2b720914aa6435ac8d3e3a4210c5b0fb2d",
+	},
 	{
 		mode: agron2.Argon2I, time: 4, memory: 4096, threads: 4,
 		hash: "a11f7b7f3f93f02ad4bddb59ab62d121e278369288a0d0e7",
@@ -57,6 +80,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 4, memory: 4096, threads: 4,
 		hash: "145db9733a9f4ee43edf33c509be96b934d505a4efb33c5a",
 	},
+	{
+		mode: agron2.Argon2D, time: 4, memory: 1024, threads: 8,
+		hash: "83604fc2ad0589b9d055578f4d3cc55bc616df3578a896e9",
+	},
 	{
 		mode: agron2.Argon2I, time: 4, memory: 1024, threads: 8,
 		hash: "0cdd3956aa35e6b475a7b0c63488822f774f15b43f6e6e17",
@@ -65,6 +92,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 4, memory: 1024, threads: 8,
 		hash: "8dafa8e004f8ea96bf7c0f93eecf67a6047476143d15577f",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 3,
+		hash: "22474a423bda2ccd36ec9afd5119e5c8949798cadf659f51",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 3,
 		hash: "5cab452fe6b8479c8661def8cd703b611a3905a6d5477fe6",
@@ -73,6 +104,10 @@ var testVectors = []struct {
 		mode: agron2.Argon2Id, time: 2, memory: 64, threads: 3,
 		hash: "4a15b31aec7c2590b87d1f520be7d96f56658172deaa3079",
 	},
+	{
+		mode: agron2.Argon2D, time: 3, memory: 1024, threads: 6,
+		hash: "a3351b0319a53229152023d9206902f4ef59661cdca89481",
+	},
 	{
 		mode: agron2.Argon2I, time: 3, memory: 1024, threads: 6,
 		hash: "d236b29c2b2a09babee842b0dec6aa1e83ccbdea8023dced",
@@ -104,6 +139,9 @@ func TestVectors(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
 		}
+		if hash != string(want) {
+			t.Errorf("Test %d - got: %x, want: %x", i, hash, want)
+		}
 
 		err = agron2.Argon2VerifyCtx(ctx, hash, v.mode)
 		if err != nil {
@@ -112,12 +150,337 @@ func TestVectors(t *testing.T) {
 	}
 }
 
+// https://www.rfc-editor.org/rfc/rfc9106.html#section-5
+var testVectorsRFC9106 = []struct {
+	mode agron2.Argon2Type
+	tag  string
+}{
+	{
+		mode: agron2.Argon2D,
+		tag:  "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb",
+	},
+	{
+		mode: agron2.Argon2I,
+		tag:  "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8",
+	},
+	{
+		mode: agron2.Argon2Id,
+		tag:  "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659",
+	},
+}
+
+func TestVectorsRFC9106(t *testing.T) {
+	for i, v := range testVectorsRFC9106 {
+		want, err := hex.DecodeString(v.tag)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decode tag: %v", i, err)
+		}
+
+		ctx := agron2.Argon2Context{
+			Version:   argon2.Version,
+			Tcost:     3,
+			Mcost:     32,
+			Threads:   4,
+			Secretlen: uint32(len(want)),
+			Pwd:       strings.Repeat("\x01", 32),
+			Salt:      strings.Repeat("\x02", 16),
+			Secret:    bytes.Repeat([]byte{0x03}, 8),
+			AD:        bytes.Repeat([]byte{0x04}, 12),
+		}
+		hash, err := agron2.Argon2Ctx(ctx, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+		if hash != string(want) {
+			t.Errorf("Test %d - got: %x, want: %x", i, hash, want)
+		}
+
+		if err = agron2.Argon2VerifyCtx(ctx, hash, v.mode); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+
+		ctx.Secret = nil
+		if err = agron2.Argon2VerifyCtx(ctx, hash, v.mode); err == nil {
+			t.Errorf("Test %d - expected mismatch without secret", i)
+		}
+
+		ctx.Secret = bytes.Repeat([]byte{0x03}, 8)
+		ctx.AD = nil
+		if err = agron2.Argon2VerifyCtx(ctx, hash, v.mode); err == nil {
+			t.Errorf("Test %d - expected mismatch without associated data", i)
+		}
+	}
+}
+
+// https://github.com/P-H-C/phc-winner-argon2/blob/master/src/test.c
+var testVectorsVersion = []struct {
+	version        int
+	time, memory   uint32
+	threads        uint8
+	password, salt string
+	hash           string
+}{
+	{
+		version: agron2.Argon2Version10, time: 2, memory: 65536, threads: 1,
+		password: "password", salt: "somesalt",
+		hash: "f6c4db4a54e2a370627aff3db6176b94a2a209a62c8e36152711802f7b30c694",
+	},
+	{
+		version: agron2.Argon2Version10, time: 2, memory: 256, threads: 1,
+		password: "password", salt: "somesalt",
+		hash: "fd4dd83d762c49bdeaf57c47bdcd0c2f1babf863fdeb490df63ede9975fccf06",
+	},
+	{
+		version: agron2.Argon2Version10, time: 2, memory: 256, threads: 2,
+		password: "password", salt: "somesalt",
+		hash: "b6c11560a6a9d61eac706b79a2f97d68b4463aa3ad87e00c07e2b01e90c564fb",
+	},
+	{
+		version: agron2.Argon2Version10, time: 1, memory: 65536, threads: 1,
+		password: "password", salt: "somesalt",
+		hash: "81630552b8f3b1f48cdb1992c4c678643d490b2b5eb4ff6c4b3438b5621724b2",
+	},
+	{
+		version: agron2.Argon2Version10, time: 2, memory: 65536, threads: 1,
+		password: "differentpassword", salt: "somesalt",
+		hash: "e9c902074b6754531a3a0be519e5baf404b30ce69b3f01ac3bf21229960109a3",
+	},
+	{
+		version: agron2.Argon2Version10, time: 2, memory: 65536, threads: 1,
+		password: "password", salt: "diffsalt",
+		hash: "79a103b90fe8aef8570cb31fc8b22259778916f8336b7bdac3892569d4f1c497",
+	},
+	{
+		version: agron2.Argon2Version13, time: 2, memory: 65536, threads: 1,
+		password: "password", salt: "somesalt",
+		hash: "c1628832147d9720c5bd1cfd61367078729f6dfb6f8fea9ff98158e0d7816ed0",
+	},
+	{
+		version: agron2.Argon2Version13, time: 2, memory: 256, threads: 1,
+		password: "password", salt: "somesalt",
+		hash: "89e9029f4637b295beb027056a7336c414fadd43f6b208645281cb214a56452f",
+	},
+	{
+		version: agron2.Argon2Version13, time: 2, memory: 256, threads: 2,
+		password: "password", salt: "somesalt",
+		hash: "4ff5ce2769a1d7f4c8a491df09d41a9fbe90e5eb02155a13e4c01e20cd4eab61",
+	},
+}
+
+func TestVectorsVersion(t *testing.T) {
+	for i, v := range testVectorsVersion {
+		want, err := hex.DecodeString(v.hash)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decode hash: %v", i, err)
+		}
+
+		ctx := agron2.Argon2Context{
+			Version:   v.version,
+			Tcost:     v.time,
+			Mcost:     v.memory,
+			Threads:   v.threads,
+			Secretlen: uint32(len(want)),
+			Pwd:       v.password,
+			Salt:      v.salt,
+		}
+		hash, err := agron2.Argon2Ctx(ctx, agron2.Argon2I)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+		if hash != string(want) {
+			t.Errorf("Test %d - got: %x, want: %x", i, hash, want)
+		}
+
+		encoded := agron2.EncodeString(ctx, agron2.Argon2I, hash)
+		if !strings.HasPrefix(encoded, fmt.Sprintf("$argon2i$v=%d$", v.version)) {
+			t.Errorf("Test %d - unexpected encoded version: %s", i, encoded)
+		}
+		if err = agron2.Argon2Verify(encoded, v.password, agron2.Argon2I); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+	}
+}
+
+func TestDecodeStringWithoutVersion(t *testing.T) {
+	// Hash strings without the version segment are treated as version 1.0
+	encoded := "$argon2i$m=256,t=2,p=1$" + hex.EncodeToString([]byte("somesalt")) +
+		"$fd4dd83d762c49bdeaf57c47bdcd0c2f1babf863fdeb490df63ede9975fccf06"
+
+	ctx, _, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if ctx.Version != agron2.Argon2Version10 {
+		t.Errorf("got version: %#x, want: %#x", ctx.Version, agron2.Argon2Version10)
+	}
+
+	if err = agron2.Argon2Verify(encoded, "password", agron2.Argon2I); err != nil {
+		t.Errorf("failed to verify: %v", err)
+	}
+
+	if err = agron2.Argon2Verify(strings.Replace(encoded, "$m=", "$v=18$m=", 1), "password", agron2.Argon2I); err == nil {
+		t.Errorf("expected error for unknown version")
+	}
+}
+
+// https://github.com/P-H-C/phc-winner-argon2/blob/master/src/test.c
+var testVectorsEncoded = []struct {
+	mode     agron2.Argon2Type
+	password string
+	encoded  string
+}{
+	{
+		mode: agron2.Argon2I, password: "password",
+		encoded: "$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ",
+	},
+	{
+		mode: agron2.Argon2I, password: "password",
+		encoded: "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+	},
+}
+
+func TestVectorsEncoded(t *testing.T) {
+	for i, v := range testVectorsEncoded {
+		if err := agron2.Argon2Verify(v.encoded, v.password, v.mode); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+
+		ctx, hash, err := agron2.DecodeString(agron2.Argon2Context{}, v.encoded, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decode: %v", i, err)
+		}
+		if ctx.Encoding != agron2.Argon2EncodingBase64 {
+			t.Errorf("Test %d - got encoding: %d, want: %d", i, ctx.Encoding, agron2.Argon2EncodingBase64)
+		}
+
+		if ctx.Version == agron2.Argon2Version13 {
+			if got := agron2.EncodeString(ctx, v.mode, hash); got != v.encoded {
+				t.Errorf("Test %d - got: %s, want: %s", i, got, v.encoded)
+			}
+		}
+	}
+}
+
+func TestDecodeStringParams(t *testing.T) {
+	hash := "wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+	for i, encoded := range []string{
+		"$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$p=1,t=2,m=65536$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$m=65536,t=2,p=1,keyid=AQID$c29tZXNhbHQ$" + hash,
+	} {
+		ctx, _, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2I)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decode: %v", i, err)
+		}
+		if ctx.Mcost != 65536 || ctx.Tcost != 2 || ctx.Threads != 1 {
+			t.Errorf("Test %d - got: m=%d,t=%d,p=%d", i, ctx.Mcost, ctx.Tcost, ctx.Threads)
+		}
+		if ctx.KeyID != "" {
+			// The key id names a pepper, which cannot be found without a keyring
+			if ctx.KeyID != "\x01\x02\x03" {
+				t.Errorf("Test %d - got key id: %x", i, ctx.KeyID)
+			}
+			if err = agron2.Argon2Verify(encoded, "password", agron2.Argon2I); !errors.Is(err, agron2.ErrUnknownKey) {
+				t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrUnknownKey)
+			}
+			continue
+		}
+		if err = agron2.Argon2Verify(encoded, "password", agron2.Argon2I); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+	}
+
+	// The associated data stored in the string takes part in verification
+	encoded := "$argon2i$v=19$m=65536,t=2,p=1,data=BAQE$c29tZXNhbHQ$" + hash
+	ctx, _, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if string(ctx.AD) != "\x04\x04\x04" {
+		t.Errorf("got associated data: %x", ctx.AD)
+	}
+	if err = agron2.Argon2Verify(encoded, "password", agron2.Argon2I); err == nil {
+		t.Errorf("expected mismatch with associated data")
+	}
+	if got := agron2.EncodeString(ctx, agron2.Argon2I, "x"); !strings.Contains(got, ",data=BAQE$") {
+		t.Errorf("got: %s, want data=BAQE", got)
+	}
+
+	// A hash of associated data survives encoding and verifies
+	ctx.Pwd = "password"
+	key, err := agron2.Argon2Ctx(ctx, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if err = agron2.Argon2Verify(agron2.EncodeString(ctx, agron2.Argon2I, key), "password", agron2.Argon2I); err != nil {
+		t.Errorf("error: %v", err)
+	}
+
+	for i, encoded := range []string{
+		"$argon2i$v=19$m=65536,t=2$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$m=65536,t=2,p=1,x=1$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$m=65536,t=2,p=01$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$m=65536,t=2,p=256$c29tZXNhbHQ$" + hash,
+		"$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ",
+		"$argon2i$v=19$m=65536,t=2,p=1,keyid=AQIDBAUGBwgJ$c29tZXNhbHQ$" + hash,
+	} {
+		if _, _, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2I); err == nil {
+			t.Errorf("Test %d - expected error for %s", i, encoded)
+		}
+	}
+}
+
+func TestEncodeStringHex(t *testing.T) {
+	ctx := agron2.Argon2Context{
+		Version:   agron2.Argon2Version13,
+		Tcost:     2,
+		Mcost:     256,
+		Threads:   1,
+		Secretlen: 32,
+		Pwd:       "password",
+		Salt:      "somesalt",
+		Encoding:  agron2.Argon2EncodingHex,
+	}
+	hash, err := agron2.Argon2Ctx(ctx, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to get argon context: %v", err)
+	}
+
+	encoded := agron2.EncodeString(ctx, agron2.Argon2I, hash)
+	want := "$argon2i$v=19$m=256,t=2,p=1$736f6d6573616c74$89e9029f4637b295beb027056a7336c414fadd43f6b208645281cb214a56452f"
+	if encoded != want {
+		t.Fatalf("got: %s, want: %s", encoded, want)
+	}
+
+	decoded, decodedHash, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if decoded.Encoding != agron2.Argon2EncodingHex {
+		t.Errorf("got encoding: %d, want: %d", decoded.Encoding, agron2.Argon2EncodingHex)
+	}
+	if got := agron2.EncodeString(decoded, agron2.Argon2I, decodedHash); got != encoded {
+		t.Errorf("got: %s, want: %s", got, encoded)
+	}
+
+	ctx.Encoding = agron2.Argon2EncodingBase64
+	want = "$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHQ$iekCn0Y3spW+sCcFanM2xBT63UP2sghkUoHLIUpWRS8"
+	if got := agron2.EncodeString(ctx, agron2.Argon2I, hash); got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
 var testVectorsPwSalt = []struct {
 	mode                 agron2.Argon2Type
 	time, memory, keyLen uint32
 	threads              uint8
 	password, salt       string
 }{
+	{
+		mode: agron2.Argon2D, time: 1, memory: 64, threads: 1, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 1, memory: 64, threads: 1, keyLen: 32,
 		password: "password",
@@ -128,6 +491,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 1, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 1, keyLen: 32,
 		password: "password",
@@ -138,6 +506,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 2, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 2, keyLen: 32,
 		password: "password",
@@ -148,6 +521,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 3, memory: 256, threads: 2, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 3, memory: 256, threads: 2, keyLen: 32,
 		password: "password",
@@ -158,6 +536,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 4, memory: 4096, threads: 4, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 4, memory: 4096, threads: 4, keyLen: 32,
 		password: "password",
@@ -168,6 +551,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 4, memory: 1024, threads: 8, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 4, memory: 1024, threads: 8, keyLen: 32,
 		password: "password",
@@ -178,6 +566,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 2, memory: 64, threads: 3, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 2, memory: 64, threads: 3, keyLen: 32,
 		password: "password",
@@ -188,6 +581,11 @@ var testVectorsPwSalt = []struct {
 		password: "password",
 		salt:     "somesalt",
 	},
+	{
+		mode: agron2.Argon2D, time: 3, memory: 1024, threads: 6, keyLen: 32,
+		password: "password",
+		salt:     "somesalt",
+	},
 	{
 		mode: agron2.Argon2I, time: 3, memory: 1024, threads: 6, keyLen: 32,
 		password: "password",
@@ -214,6 +612,76 @@ func TestVectorsPwSalt(t *testing.T) {
 	}
 }
 
+func TestArgon2dEncodeDecode(t *testing.T) {
+	password, salt := "password", "somesalt"
+	encoded, err := agron2.Argon2Hash(password, salt, 3, 256, 2, 32, argon2.Version, agron2.Argon2D)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2d$") {
+		t.Fatalf("unexpected encoded prefix: %s", encoded)
+	}
+
+	ctx, hash, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2D)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	ctx.Pwd = password
+	if got := agron2.EncodeString(ctx, agron2.Argon2D, hash); got != encoded {
+		t.Errorf("got: %s, want: %s", got, encoded)
+	}
+
+	if err := agron2.Argon2Verify(encoded, password, agron2.Argon2D); err != nil {
+		t.Errorf("failed to verify: %v", err)
+	}
+	if err := agron2.Argon2Verify(encoded, "wrong", agron2.Argon2D); err == nil {
+		t.Errorf("expected mismatch for wrong password")
+	}
+	if err := agron2.Argon2Verify(encoded, password, agron2.Argon2Id); err == nil {
+		t.Errorf("expected error for wrong type")
+	}
+}
+
+func TestArgon2String2Type(t *testing.T) {
+	for _, types := range []agron2.Argon2Type{agron2.Argon2D, agron2.Argon2I, agron2.Argon2Id} {
+		got, err := agron2.Argon2String2Type(agron2.Argon2Type2String(types, false))
+		if err != nil || got != types {
+			t.Errorf("%v - got: %v, %v", types, got, err)
+		}
+	}
+
+	for _, str := range []string{"", "argon2", "Argon2id", "Argon2d", "ARGON2ID", "scrypt"} {
+		if _, err := agron2.Argon2String2Type(str); !errors.Is(err, agron2.ErrIncorrectType) {
+			t.Errorf("%q - got: %v, want: %v", str, err, agron2.ErrIncorrectType)
+		}
+	}
+}
+
+func TestArgon2VerifyAny(t *testing.T) {
+	for i, v := range testVectorsEncoded {
+		if err := agron2.Argon2VerifyAny(v.encoded, v.password); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+		if err := agron2.Argon2VerifyAny(v.encoded, v.password, agron2.Argon2Id, v.mode); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+		if err := agron2.Argon2VerifyAny(v.encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+	}
+
+	encoded, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, argon2.Version, agron2.Argon2D)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if err := agron2.Argon2VerifyAny(encoded, "password", agron2.Argon2I, agron2.Argon2Id); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+	if err := agron2.Argon2VerifyAny("$scrypt$ln=15,r=8,p=1$c29tZXNhbHQ$c29tZWhhc2g", "password"); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+}
+
 func benchmarkArgon2(mode agron2.Argon2Type, time, memory uint32, threads uint8, keyLen uint32, b *testing.B) {
 	password := "password"
 	salt := "choosing random salts is hard"
@@ -233,6 +701,15 @@ func benchmarkArgon2(mode agron2.Argon2Type, time, memory uint32, threads uint8,
 	}
 }
 
+func BenchmarkArgon2d(b *testing.B) {
+	b.Run(" Time: 3 Memory: 32 MB, Threads: 1", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 3, 32*1024, 1, 32, b) })
+	b.Run(" Time: 4 Memory: 32 MB, Threads: 1", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 4, 32*1024, 1, 32, b) })
+	b.Run(" Time: 5 Memory: 32 MB, Threads: 1", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 5, 32*1024, 1, 32, b) })
+	b.Run(" Time: 3 Memory: 64 MB, Threads: 4", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 3, 64*1024, 4, 32, b) })
+	b.Run(" Time: 4 Memory: 64 MB, Threads: 4", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 4, 64*1024, 4, 32, b) })
+	b.Run(" Time: 5 Memory: 64 MB, Threads: 4", func(b *testing.B) { benchmarkArgon2(agron2.Argon2D, 5, 64*1024, 4, 32, b) })
+}
+
 func BenchmarkArgon2i(b *testing.B) {
 	b.Run(" Time: 3 Memory: 32 MB, Threads: 1", func(b *testing.B) { benchmarkArgon2(agron2.Argon2I, 3, 32*1024, 1, 32, b) })
 	b.Run(" Time: 4 Memory: 32 MB, Threads: 1", func(b *testing.B) { benchmarkArgon2(agron2.Argon2I, 4, 32*1024, 1, 32, b) })
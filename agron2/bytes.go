@@ -0,0 +1,42 @@
+package agron2
+
+// Argon2CtxBytes is like Argon2Ctx but takes the password as a byte slice and
+// ignores context.Pwd. The password is never copied into a string, and every
+// intermediate buffer, including the Argon2 memory, is zeroed before it
+// returns. The returned key belongs to the caller.
+func Argon2CtxBytes(context Argon2Context, pwd []byte, types Argon2Type) ([]byte, error) {
+	return argon2Key(background, context, pwd, types)
+}
+
+// Argon2HashBytes is like Argon2Hash but takes the password as a byte slice.
+func Argon2HashBytes(password []byte, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
+	ctx := Argon2Context{
+		Salt:      salt,
+		Secretlen: keyLen,
+		Mcost:     memory,
+		Threads:   threads,
+		Tcost:     time,
+		Version:   version,
+	}
+
+	key, err := argon2Key(background, ctx, password, types)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(key)
+
+	ret := encodeString(ctx, types, key)
+	return ret, nil
+}
+
+// Argon2VerifyBytes is like Argon2Verify but takes the password as a byte
+// slice.
+func Argon2VerifyBytes(encoded string, pwd []byte, types Argon2Type) error {
+	return Verifier{}.VerifyBytes(background, encoded, pwd, types)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,67 @@
+package agron2_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func TestArgon2CtxBytes(t *testing.T) {
+	for i, v := range testVectors {
+		ctx := agron2.Argon2Context{
+			Version:   agron2.Argon2Version13,
+			Tcost:     v.time,
+			Mcost:     v.memory,
+			Threads:   v.threads,
+			Secretlen: 24,
+			Salt:      "somesalt",
+		}
+		pwd := []byte("password")
+
+		key, err := agron2.Argon2CtxBytes(ctx, pwd, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+
+		ctx.Pwd = "password"
+		want, err := agron2.Argon2Ctx(ctx, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+		if string(key) != want {
+			t.Errorf("Test %d - got: %x, want: %x", i, key, want)
+		}
+		if string(pwd) != "password" {
+			t.Errorf("Test %d - password buffer was modified: %q", i, pwd)
+		}
+	}
+}
+
+func TestArgon2HashBytes(t *testing.T) {
+	pwd := []byte("password")
+	encoded, err := agron2.Argon2HashBytes(pwd, "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	want, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil || encoded != want {
+		t.Errorf("got: %s, want: %s, %v", encoded, want, err)
+	}
+
+	if err = agron2.Argon2VerifyBytes(encoded, pwd, agron2.Argon2Id); err != nil {
+		t.Errorf("failed to verify: %v", err)
+	}
+	if !bytes.Equal(pwd, []byte("password")) {
+		t.Errorf("password buffer was modified: %q", pwd)
+	}
+
+	if err = agron2.Argon2VerifyBytes(encoded, []byte("wrong"), agron2.Argon2Id); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if err = agron2.Argon2VerifyBytes(encoded, nil, agron2.Argon2Id); !errors.Is(err, agron2.ErrPwdPtrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrPwdPtrMismatch)
+	}
+}
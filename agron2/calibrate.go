@@ -0,0 +1,174 @@
+package agron2
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/envelope"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+const (
+	Argon2DefaultSaltLength uint32 = 16 // Salt length in bytes used when none is given
+	Argon2DefaultKeyLength  uint32 = 32 // Hash length in bytes used when none is given
+)
+
+// Argon2Policy is a set of hashing parameters that new hashes should be
+// computed with.
+type Argon2Policy struct {
+	Type    Argon2Type
+	Version int    // version number, zero means the latest version
+	Mcost   uint32 // amount of memory requested (KB)
+	Tcost   uint32 // number of passes
+	Threads uint8  // number of lanes
+	SaltLen uint32 // salt length in bytes, zero means Argon2DefaultSaltLength
+	KeyLen  uint32 // hash length in bytes, zero means Argon2DefaultKeyLength
+
+	Keys     *pepper.Keyring  // pepper of new hashes, none if nil
+	Envelope *envelope.Sealer // encryption of new hashes at rest, none if nil
+}
+
+// Context returns an Argon2Context with the policy parameters and without
+// password and salt.
+func (p Argon2Policy) Context() Argon2Context {
+	keyLen := p.KeyLen
+	if keyLen == 0 {
+		keyLen = Argon2DefaultKeyLength
+	}
+
+	return Argon2Context{
+		Secretlen: keyLen,
+		Mcost:     p.Mcost,
+		Threads:   p.Threads,
+		Tcost:     p.Tcost,
+		Version:   p.Version,
+	}
+}
+
+type Argon2Trial struct {
+	Mcost   uint32
+	Tcost   uint32
+	Threads uint8
+	Elapsed time.Duration
+}
+
+type Argon2Calibration struct {
+	Policy  Argon2Policy
+	Elapsed time.Duration // measured duration of Policy, may exceed the target when even the smallest memory is too slow
+	Trials  []Argon2Trial // every timed hash in the order it was run
+}
+
+// Calibrator searches for the Argon2 parameters that use the most memory
+// allowed while a single hash stays within a latency target.
+type Calibrator struct {
+	Type      Argon2Type
+	Version   int
+	SaltLen   uint32
+	KeyLen    uint32
+	MaxTrials int                                             // upper bound of timed hashes, 16 if zero
+	Now       func() time.Time                                // clock used to time trials, time.Now if nil
+	Hash      func(Argon2Context, Argon2Type) (string, error) // hash function to time, Argon2Ctx if nil
+}
+
+// Calibrate runs Calibrator.Calibrate for Argon2id.
+func Calibrate(target time.Duration, maxMemoryKiB uint32, maxThreads uint8) (Argon2Calibration, error) {
+	return Calibrator{Type: Argon2Id}.Calibrate(target, maxMemoryKiB, maxThreads)
+}
+
+// Calibrate first halves the memory from maxMemoryKiB until a single pass
+// fits in target, then raises the number of passes as far as the budget
+// allows. The number of lanes is maxThreads capped at GOMAXPROCS.
+func (c Calibrator) Calibrate(target time.Duration, maxMemoryKiB uint32, maxThreads uint8) (Argon2Calibration, error) {
+	if target <= 0 {
+		return Argon2Calibration{}, newError(Argon2TimeTooSmall, "target")
+	}
+
+	threads := maxThreads
+	if procs := runtime.GOMAXPROCS(0); procs < int(threads) {
+		threads = uint8(procs)
+	}
+	if threads < 1 {
+		return Argon2Calibration{}, newError(Argon2ThreadsTooFew, "maxThreads")
+	}
+
+	minMemory := Argon2MinMemory
+	if lanesMemory := 8 * uint32(threads); lanesMemory > minMemory {
+		minMemory = lanesMemory
+	}
+	if maxMemoryKiB < minMemory {
+		return Argon2Calibration{}, newError(Argon2MemoryTooLittle, "maxMemoryKiB")
+	}
+
+	maxTrials := c.MaxTrials
+	if maxTrials <= 0 {
+		maxTrials = 16
+	}
+	now := c.Now
+	if now == nil {
+		now = time.Now
+	}
+	hash := c.Hash
+	if hash == nil {
+		hash = Argon2Ctx
+	}
+
+	ret := Argon2Calibration{
+		Policy: Argon2Policy{
+			Type:    c.Type,
+			Version: c.Version,
+			Threads: threads,
+			SaltLen: c.SaltLen,
+			KeyLen:  c.KeyLen,
+		},
+	}
+
+	measure := func(memory, passes uint32) (time.Duration, error) {
+		ctx := ret.Policy.Context()
+		ctx.Pwd = "password"
+		ctx.Salt = "calibration salt"
+		ctx.Mcost = memory
+		ctx.Tcost = passes
+
+		start := now()
+		if _, err := hash(ctx, c.Type); err != nil {
+			return 0, err
+		}
+		elapsed := now().Sub(start)
+
+		ret.Trials = append(ret.Trials, Argon2Trial{Mcost: memory, Tcost: passes, Threads: threads, Elapsed: elapsed})
+		return elapsed, nil
+	}
+
+	// Use as much memory as a single pass allows
+	memory := maxMemoryKiB
+	elapsed, err := measure(memory, 1)
+	if err != nil {
+		return Argon2Calibration{}, err
+	}
+	for elapsed > target && memory/2 >= minMemory && len(ret.Trials) < maxTrials {
+		memory /= 2
+		if elapsed, err = measure(memory, 1); err != nil {
+			return Argon2Calibration{}, err
+		}
+	}
+	ret.Policy.Mcost, ret.Policy.Tcost, ret.Elapsed = memory, 1, elapsed
+
+	// Spend the rest of the budget on passes, guessing from the single pass
+	// and stepping back while the guess is too slow
+	passes := uint32(1)
+	if elapsed > 0 {
+		passes = uint32(Argon2Min(uint64(target/elapsed), uint64(Argon2MaxTime)))
+	}
+	for passes > 1 && len(ret.Trials) < maxTrials {
+		if elapsed, err = measure(memory, passes); err != nil {
+			return Argon2Calibration{}, err
+		}
+		if elapsed <= target {
+			ret.Policy.Tcost, ret.Elapsed = passes, elapsed
+			break
+		}
+		passes--
+	}
+
+	return ret, nil
+}
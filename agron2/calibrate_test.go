@@ -0,0 +1,115 @@
+package agron2_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+// fakeClock advances by one microsecond per KiB and pass of every hash.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Hash(ctx agron2.Argon2Context, types agron2.Argon2Type) (string, error) {
+	c.now = c.now.Add(time.Duration(ctx.Mcost) * time.Duration(ctx.Tcost) * time.Microsecond)
+	return "", nil
+}
+
+var testCalibrate = []struct {
+	target       time.Duration
+	maxMemoryKiB uint32
+	mcost, tcost uint32
+	trials       []agron2.Argon2Trial
+}{
+	{
+		target: 100 * time.Millisecond, maxMemoryKiB: 64 * 1024,
+		mcost: 64 * 1024, tcost: 1,
+		trials: []agron2.Argon2Trial{
+			{Mcost: 64 * 1024, Tcost: 1, Threads: 1, Elapsed: 65536 * time.Microsecond},
+		},
+	},
+	{
+		target: 100 * time.Millisecond, maxMemoryKiB: 16 * 1024,
+		mcost: 16 * 1024, tcost: 6,
+		trials: []agron2.Argon2Trial{
+			{Mcost: 16 * 1024, Tcost: 1, Threads: 1, Elapsed: 16384 * time.Microsecond},
+			{Mcost: 16 * 1024, Tcost: 6, Threads: 1, Elapsed: 6 * 16384 * time.Microsecond},
+		},
+	},
+	{
+		target: 100 * time.Millisecond, maxMemoryKiB: 256 * 1024,
+		mcost: 64 * 1024, tcost: 1,
+		trials: []agron2.Argon2Trial{
+			{Mcost: 256 * 1024, Tcost: 1, Threads: 1, Elapsed: 262144 * time.Microsecond},
+			{Mcost: 128 * 1024, Tcost: 1, Threads: 1, Elapsed: 131072 * time.Microsecond},
+			{Mcost: 64 * 1024, Tcost: 1, Threads: 1, Elapsed: 65536 * time.Microsecond},
+		},
+	},
+	{
+		// Even the smallest memory misses the target
+		target: time.Microsecond, maxMemoryKiB: 64,
+		mcost: 8, tcost: 1,
+		trials: []agron2.Argon2Trial{
+			{Mcost: 64, Tcost: 1, Threads: 1, Elapsed: 64 * time.Microsecond},
+			{Mcost: 32, Tcost: 1, Threads: 1, Elapsed: 32 * time.Microsecond},
+			{Mcost: 16, Tcost: 1, Threads: 1, Elapsed: 16 * time.Microsecond},
+			{Mcost: 8, Tcost: 1, Threads: 1, Elapsed: 8 * time.Microsecond},
+		},
+	},
+}
+
+func TestCalibrate(t *testing.T) {
+	for i, v := range testCalibrate {
+		clock := &fakeClock{}
+		c := agron2.Calibrator{Type: agron2.Argon2Id, Now: clock.Now, Hash: clock.Hash}
+
+		got, err := c.Calibrate(v.target, v.maxMemoryKiB, 1)
+		if err != nil {
+			t.Fatalf("Test %d: failed to calibrate: %v", i, err)
+		}
+		if got.Policy.Mcost != v.mcost || got.Policy.Tcost != v.tcost || got.Policy.Threads != 1 {
+			t.Errorf("Test %d - got: m=%d,t=%d,p=%d, want: m=%d,t=%d,p=1", i, got.Policy.Mcost, got.Policy.Tcost, got.Policy.Threads, v.mcost, v.tcost)
+		}
+		if !reflect.DeepEqual(got.Trials, v.trials) {
+			t.Errorf("Test %d - got trials: %+v, want: %+v", i, got.Trials, v.trials)
+		}
+		if want := v.trials[len(v.trials)-1].Elapsed; got.Elapsed != want {
+			t.Errorf("Test %d - got elapsed: %v, want: %v", i, got.Elapsed, want)
+		}
+	}
+}
+
+func TestCalibrateInvalid(t *testing.T) {
+	if _, err := agron2.Calibrate(0, 1024, 1); err == nil {
+		t.Errorf("expected error for zero target")
+	}
+	if _, err := agron2.Calibrate(time.Second, 1024, 0); err == nil {
+		t.Errorf("expected error for zero threads")
+	}
+	if _, err := agron2.Calibrate(time.Second, 4, 1); err == nil {
+		t.Errorf("expected error for too little memory")
+	}
+}
+
+func TestCalibrateHash(t *testing.T) {
+	got, err := agron2.Calibrate(20*time.Millisecond, 1024, 1)
+	if err != nil {
+		t.Fatalf("failed to calibrate: %v", err)
+	}
+
+	ctx := got.Policy.Context()
+	ctx.Pwd, ctx.Salt = "password", "somesalt"
+	if code := agron2.ValidateInputs(ctx); code != agron2.Argon2Ok {
+		t.Errorf("calibrated policy is invalid: %s", agron2.Argon2ErrorMessage(code))
+	}
+	if got.Policy.Type != agron2.Argon2Id || got.Policy.Mcost > 1024 || len(got.Trials) == 0 {
+		t.Errorf("unexpected calibration: %+v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package agron2
+
+import "context"
+
+// background stands in for context.Background where a parameter named
+// context shadows the package.
+var background = context.Background()
+
+// Argon2CtxContext is like Argon2Ctx but stops between passes and segments
+// once ctx is done, zeroing its memory and returning ctx.Err().
+func Argon2CtxContext(ctx context.Context, actx Argon2Context, types Argon2Type) (string, error) {
+	pwd := []byte(actx.Pwd)
+	defer wipe(pwd)
+
+	key, err := argon2Key(ctx, actx, pwd, types)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(key)
+
+	ret := string(key)
+	return ret, nil
+}
+
+// Argon2HashContext is like Argon2Hash but stops once ctx is done, see
+// Argon2CtxContext.
+func Argon2HashContext(ctx context.Context, password, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
+	switch types {
+	case Argon2D, Argon2I, Argon2Id:
+	default:
+		return "", newError(Argon2IncorrectType, "")
+	}
+
+	actx := Argon2Context{
+		Pwd:       password,
+		Salt:      salt,
+		Secretlen: keyLen,
+		Mcost:     memory,
+		Threads:   threads,
+		Tcost:     time,
+		Version:   version,
+	}
+
+	key, err := Argon2CtxContext(ctx, actx, types)
+	if err != nil {
+		return "", err
+	}
+
+	ret := EncodeString(actx, types, key)
+	return ret, nil
+}
+
+// Argon2VerifyContext is like Argon2Verify but stops once ctx is done, see
+// Argon2CtxContext.
+func Argon2VerifyContext(ctx context.Context, encoded, pwd string, types Argon2Type) error {
+	pwdBytes := []byte(pwd)
+	defer wipe(pwdBytes)
+
+	return Verifier{}.VerifyBytes(ctx, encoded, pwdBytes, types)
+}
+
+type workersKey struct{}
+
+// withWorkers returns ctx under which hashes run at most n goroutines at once,
+// whatever their Threads, which stay the parallelism of the hash.
+func withWorkers(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, workersKey{}, n)
+}
+
+// maxWorkers returns the goroutines a hash of threads lanes runs at once
+// under ctx.
+func maxWorkers(ctx context.Context, threads uint32) uint32 {
+	if n, ok := ctx.Value(workersKey{}).(int); ok && n > 0 && uint32(n) < threads {
+		return uint32(n)
+	}
+
+	return threads
+}
+
+func argon2Key(ctx context.Context, actx Argon2Context, pwd []byte, types Argon2Type) ([]byte, error) {
+	if errs := validateInputs(actx, len(pwd), false); len(errs) != 0 {
+		return nil, errs[0]
+	}
+
+	switch types {
+	case Argon2D, Argon2I, Argon2Id:
+	default:
+		return nil, newError(Argon2IncorrectType, "")
+	}
+
+	version := actx.Version
+	if version == 0 {
+		version = Argon2VersionNumber
+	}
+
+	salt := []byte(actx.Salt)
+	return deriveKey(ctx, types, version, pwd, salt, actx.Secret, actx.AD, actx.Tcost, actx.Mcost, actx.Threads, actx.Secretlen)
+}
@@ -0,0 +1,81 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func TestArgon2CtxContext(t *testing.T) {
+	for i, v := range testVectors {
+		ctx := agron2.Argon2Context{
+			Version:   agron2.Argon2Version13,
+			Tcost:     v.time,
+			Mcost:     v.memory,
+			Threads:   v.threads,
+			Secretlen: 24,
+			Pwd:       "password",
+			Salt:      "somesalt",
+		}
+
+		key, err := agron2.Argon2CtxContext(context.Background(), ctx, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+
+		want, err := agron2.Argon2Ctx(ctx, v.mode)
+		if err != nil {
+			t.Fatalf("Test %d: failed to get argon context: %v", i, err)
+		}
+		if key != want {
+			t.Errorf("Test %d - got: %x, want: %x", i, key, want)
+		}
+	}
+}
+
+func TestArgon2ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := agron2.Argon2HashContext(ctx, "password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("hash - got: %v, want: %v", err, context.Canceled)
+	}
+
+	encoded, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	err = agron2.Argon2VerifyContext(ctx, encoded, "password", agron2.Argon2Id)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("verify - got: %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestArgon2ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	// Large enough to take far longer than the deadline
+	_, err := agron2.Argon2HashContext(ctx, "password", "somesalt", agron2.Argon2MaxTime, 64*1024, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestArgon2VerifyContext(t *testing.T) {
+	encoded, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	if err := agron2.Argon2VerifyContext(context.Background(), encoded, "password", agron2.Argon2Id); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := agron2.Argon2VerifyContext(context.Background(), encoded, "wrong", agron2.Argon2Id); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+}
@@ -0,0 +1,98 @@
+package agron2
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// https://www.rfc-editor.org/rfc/rfc9106.html#section-5
+func TestDeriveKeyRFC9106(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	vectors := []struct {
+		mode Argon2Type
+		tag  string
+	}{
+		{
+			mode: Argon2D,
+			tag:  "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb",
+		},
+	}
+
+	for i, v := range vectors {
+		want, err := hex.DecodeString(v.tag)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decode tag: %v", i, err)
+		}
+
+		got, _ := deriveKey(context.Background(), v.mode, Argon2Version13, password, salt, secret, data, 3, 32, 4, 32)
+		if !bytes.Equal(got, want) {
+			t.Errorf("Test %d - got: %x, want: %x", i, got, want)
+		}
+	}
+}
+
+func TestDeriveKeyMatchesXCrypto(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+	for _, threads := range []uint8{1, 2, 3, 4} {
+		got, _ := deriveKey(context.Background(), Argon2I, Argon2Version13, password, salt, nil, nil, 2, 256, threads, 32)
+		if want := argon2.Key(password, salt, 2, 256, threads, 32); !bytes.Equal(got, want) {
+			t.Errorf("Argon2i threads %d - got: %x, want: %x", threads, got, want)
+		}
+
+		got, _ = deriveKey(context.Background(), Argon2Id, Argon2Version13, password, salt, nil, nil, 2, 256, threads, 32)
+		if want := argon2.IDKey(password, salt, 2, 256, threads, 32); !bytes.Equal(got, want) {
+			t.Errorf("Argon2id threads %d - got: %x, want: %x", threads, got, want)
+		}
+	}
+}
+
+// countingContext is canceled once Err has been called after calls times.
+type countingContext struct {
+	context.Context
+
+	mu    sync.Mutex
+	calls int
+	after int
+}
+
+func (c *countingContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.after > 0 && c.calls > c.after {
+		return context.Canceled
+	}
+
+	return nil
+}
+
+func TestProcessBlocksPollsPerSegment(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+
+	ctx := &countingContext{Context: context.Background()}
+	if _, err := deriveKey(ctx, Argon2Id, Argon2Version13, password, salt, nil, nil, 2, 256, 4, 32); err != nil {
+		t.Fatalf("failed to derive key: %v", err)
+	}
+	if want := 2 * syncPoints * 4; ctx.calls != want {
+		t.Errorf("got: %d, want: %d", ctx.calls, want)
+	}
+
+	// Canceled between two segments of the same slice
+	ctx = &countingContext{Context: context.Background(), after: 2}
+	if _, err := deriveKey(withWorkers(ctx, 1), Argon2Id, Argon2Version13, password, salt, nil, nil, 2, 256, 4, 32); err != context.Canceled {
+		t.Errorf("got: %v, want: %v", err, context.Canceled)
+	}
+	if ctx.calls != 3 {
+		t.Errorf("got: %d calls, want: 3", ctx.calls)
+	}
+}
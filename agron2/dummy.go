@@ -0,0 +1,85 @@
+package agron2
+
+import (
+	"context"
+	"sync"
+)
+
+// dummyPwd is verified against the decoys, it never matches as they are
+// hashed from a random password.
+const dummyPwd = "dummy password"
+
+var decoys struct {
+	mu     sync.Mutex
+	hashes map[Argon2Policy]string
+}
+
+// PrepareDummy computes the decoy hash of policy used by DummyVerify, so that
+// the first DummyVerify does not take twice as long as a real verification.
+func PrepareDummy(policy Argon2Policy) error {
+	_, err := decoy(policy)
+	return err
+}
+
+// decoy returns the process-wide decoy hash of policy, computing it on first
+// use.
+func decoy(policy Argon2Policy) (string, error) {
+	decoys.mu.Lock()
+	encoded, ok := decoys.hashes[policy]
+	decoys.mu.Unlock()
+	if ok {
+		return encoded, nil
+	}
+
+	pwd, err := Argon2GenerateSalt(nil, Argon2MinRandomSaltLength)
+	if err != nil {
+		return "", err
+	}
+	encoded, err = Argon2HashPolicy(nil, pwd, policy)
+	if err != nil {
+		return "", err
+	}
+
+	decoys.mu.Lock()
+	defer decoys.mu.Unlock()
+	if ret, ok := decoys.hashes[policy]; ok {
+		return ret, nil
+	}
+	if decoys.hashes == nil {
+		decoys.hashes = make(map[Argon2Policy]string)
+	}
+	decoys.hashes[policy] = encoded
+	return encoded, nil
+}
+
+// DummyVerify runs Verifier.DummyVerify with the zero Verifier.
+func DummyVerify(policy Argon2Policy) error {
+	return Verifier{}.DummyVerify(background, policy)
+}
+
+// DummyVerify spends as long as verifying a hash computed with policy, for
+// instance when the user does not exist, so that the response time does not
+// tell whether it does. It returns ErrMismatch unless policy is invalid or
+// ctx is done.
+func (v Verifier) DummyVerify(ctx context.Context, policy Argon2Policy) error {
+	start := v.now()
+	err := v.dummyVerify(ctx, policy)
+	v.pad(ctx, start)
+	return err
+}
+
+func (v Verifier) dummyVerify(ctx context.Context, policy Argon2Policy) error {
+	encoded, err := decoy(policy)
+	if err != nil {
+		return err
+	}
+
+	// The decoy is peppered and sealed like the real hashes of policy
+	v.Keys, v.Envelope = policy.Keys, policy.Envelope
+	pwd := []byte(dummyPwd)
+	if err = v.verifyBytes(ctx, encoded, pwd, policy.Type, false); err == nil {
+		err = newError(Argon2VerifyMismatch, "")
+	}
+
+	return err
+}
@@ -0,0 +1,51 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func TestDummyVerify(t *testing.T) {
+	policy := agron2.Argon2Policy{Type: agron2.Argon2Id, Mcost: 64, Tcost: 2, Threads: 1}
+	if err := agron2.PrepareDummy(policy); err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := agron2.DummyVerify(policy); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+	}
+
+	policy.Tcost = 0
+	if err := agron2.DummyVerify(policy); !errors.Is(err, agron2.ErrTimeTooSmall) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrTimeTooSmall)
+	}
+}
+
+func TestVerifierMinDuration(t *testing.T) {
+	encoded, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	v := agron2.Verifier{MinDuration: 50 * time.Millisecond}
+	for i, verify := range []func() error{
+		func() error { return v.Verify(context.Background(), encoded, "password", agron2.Argon2Id) },
+		func() error { return v.VerifyAny(context.Background(), encoded, "wrong") },
+		func() error { return v.VerifyAny(context.Background(), "$argon2id$", "password") },
+		func() error {
+			return v.DummyVerify(context.Background(), agron2.Argon2Policy{Type: agron2.Argon2Id, Mcost: 64, Tcost: 2, Threads: 1})
+		},
+	} {
+		start := time.Now()
+		verify()
+		if elapsed := time.Since(start); elapsed < v.MinDuration {
+			t.Errorf("Test %d - took %v, want at least %v", i, elapsed, v.MinDuration)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/envelope"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+func TestEnvelopeVerify(t *testing.T) {
+	sealer := &envelope.Sealer{Keys: &pepper.Keyring{
+		Provider: pepper.Static{"e1": []byte("0123456789abcdef0123456789abcdef")},
+		Current:  "e1",
+	}}
+	policy := testPolicy
+	policy.Envelope = sealer
+
+	sealed, err := agron2.Argon2HashPolicy(nil, "password", policy)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !envelope.IsSealed(sealed) {
+		t.Fatalf("got an unsealed hash: %s", sealed)
+	}
+
+	v := agron2.Verifier{Envelope: sealer}
+	if err := v.VerifyAny(context.Background(), sealed, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := v.Verify(context.Background(), sealed, "wrong", agron2.Argon2Id); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if err := agron2.Argon2VerifyAny(sealed, "password"); !errors.Is(err, agron2.ErrDecoding) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrDecoding)
+	}
+
+	needsRehash, err := agron2.NeedsRehash(sealed, policy)
+	if err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+	if err := agron2.DummyVerify(policy); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+}
+
+func TestEnvelopeVerifyLimits(t *testing.T) {
+	sealer := &envelope.Sealer{Keys: &pepper.Keyring{
+		Provider: pepper.Static{"e1": []byte("0123456789abcdef0123456789abcdef")},
+		Current:  "e1",
+	}}
+	v := agron2.Verifier{Envelope: sealer}
+
+	// The plaintext is over the bound of an encoded string, not the envelope
+	hash := "wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+	sealed, err := sealer.Seal("$argon2i$v=19$m=65536,t=2,p=1$" + strings.Repeat("c29tZXNhbHQ", 140) + "$" + hash)
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	if err := v.Verify(context.Background(), sealed, "password", agron2.Argon2I); !errors.Is(err, agron2.ErrDecoding) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrDecoding)
+	}
+
+	if err := v.Verify(context.Background(), sealed+strings.Repeat("A", 4096), "password", agron2.Argon2I); !errors.Is(err, agron2.ErrDecoding) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrDecoding)
+	}
+}
@@ -0,0 +1,53 @@
+package agron2
+
+import "github.com/fikryfahrezy/crypt/kdf"
+
+// Sentinel errors of the Argon2 error codes, those of kdf.
+var (
+	ErrPwdTooShort       = kdf.ErrPwdTooShort
+	ErrPwdTooLong        = kdf.ErrPwdTooLong
+	ErrSaltTooShort      = kdf.ErrSaltTooShort
+	ErrSaltTooLong       = kdf.ErrSaltTooLong
+	ErrSecretTooShort    = kdf.ErrSecretTooShort
+	ErrSecretTooLong     = kdf.ErrSecretTooLong
+	ErrTimeTooSmall      = kdf.ErrTimeTooSmall
+	ErrTimeTooLarge      = kdf.ErrTimeTooLarge
+	ErrMemoryTooLittle   = kdf.ErrMemoryTooLittle
+	ErrMemoryTooMuch     = kdf.ErrMemoryTooMuch
+	ErrPwdPtrMismatch    = kdf.ErrPwdPtrMismatch
+	ErrSaltPtrMismatch   = kdf.ErrSaltPtrMismatch
+	ErrSecretPtrMismatch = kdf.ErrSecretPtrMismatch
+	ErrIncorrectType     = kdf.ErrIncorrectType
+	ErrThreadsTooFew     = kdf.ErrThreadsTooFew
+	ErrThreadsTooMany    = kdf.ErrThreadsTooMany
+	ErrDecoding          = kdf.ErrDecoding
+	ErrMismatch          = kdf.ErrMismatch
+	ErrAdTooShort        = kdf.ErrAdTooShort
+	ErrAdTooLong         = kdf.ErrAdTooLong
+	ErrIncorrectVersion  = kdf.ErrIncorrectVersion
+	ErrSaltLowEntropy    = kdf.ErrSaltLowEntropy
+	ErrTooWeak           = kdf.ErrTooWeak
+	ErrUnknownKey        = kdf.ErrUnknownKey
+	ErrUnknownErrorCode  = kdf.ErrUnknownErrorCode
+)
+
+// Argon2Error returns the sentinel error for an Argon2 error code, or nil for
+// Argon2Ok.
+func Argon2Error(errorCode int) error {
+	return kdf.Sentinel(errorCode)
+}
+
+// Error is an Argon2 error code together with the field that caused it, the
+// kdf.Error every hashing package returns.
+type Error = kdf.Error
+
+// Errors holds every violation found by ValidateAll.
+type Errors = kdf.Errors
+
+func newError(code int, field string) *Error {
+	return kdf.NewError(code, field)
+}
+
+func decodingError(field string, err error) *Error {
+	return kdf.DecodingError(field, err)
+}
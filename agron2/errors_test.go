@@ -0,0 +1,100 @@
+package agron2_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+func TestErrors(t *testing.T) {
+	encoded, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2VersionNumber, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	err = agron2.Argon2Verify(encoded, "wrong", agron2.Argon2Id)
+	if !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+
+	var e *agron2.Error
+	if !errors.As(err, &e) || e.Code != agron2.Argon2VerifyMismatch {
+		t.Errorf("got: %#v, want code: %d", e, agron2.Argon2VerifyMismatch)
+	}
+
+	err = agron2.Argon2Verify(encoded, "password", agron2.Argon2I)
+	if !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+
+	err = agron2.Argon2Verify("$argon2id$v=19$m=64,t=x,p=1$c29tZXNhbHQ$aGFzaA", "password", agron2.Argon2Id)
+	if !errors.Is(err, agron2.ErrDecoding) || !errors.Is(err, phc.ErrInvalid) {
+		t.Errorf("got: %v, want: %v and %v", err, agron2.ErrDecoding, phc.ErrInvalid)
+	}
+	if !errors.As(err, &e) || e.Field != "t" {
+		t.Errorf("got: %#v, want field: t", e)
+	}
+
+	err = agron2.Argon2Verify("$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$!!", "password", agron2.Argon2Id)
+	if !errors.Is(err, agron2.ErrDecoding) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrDecoding)
+	}
+
+	for code := agron2.Argon2PwdTooShort; code <= agron2.Argon2UnknownKey; code++ {
+		if got := agron2.Argon2Error(code); got == nil || got.Error() != agron2.Argon2ErrorMessage(code) {
+			t.Errorf("code %d - got: %v", code, got)
+		}
+	}
+	if agron2.Argon2Error(agron2.Argon2Ok) != nil {
+		t.Errorf("expected nil error for Argon2Ok")
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	ctx := agron2.Argon2Context{
+		Pwd:       "password",
+		Salt:      "salt",
+		Secretlen: 32,
+		Mcost:     64,
+		Threads:   0,
+		Tcost:     0,
+	}
+
+	if got := agron2.ValidateInputs(ctx); got != agron2.Argon2SaltTooShort {
+		t.Errorf("got: %d, want: %d", got, agron2.Argon2SaltTooShort)
+	}
+
+	err := agron2.ValidateAll(ctx)
+	var errs agron2.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got: %v, want Errors", err)
+	}
+
+	want := []struct {
+		code  int
+		field string
+	}{
+		{agron2.Argon2SaltTooShort, "Salt"},
+		{agron2.Argon2TimeTooSmall, "Tcost"},
+		{agron2.Argon2ThreadsTooFew, "Threads"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got: %v, want %d errors", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Code != w.code || errs[i].Field != w.field {
+			t.Errorf("Error %d - got: %d %s, want: %d %s", i, errs[i].Code, errs[i].Field, w.code, w.field)
+		}
+	}
+
+	if !errors.Is(err, agron2.ErrTimeTooSmall) {
+		t.Errorf("expected %v in %v", agron2.ErrTimeTooSmall, err)
+	}
+
+	ctx.Salt, ctx.Tcost, ctx.Threads = "somesalt", 1, 1
+	if err := agron2.ValidateAll(ctx); err != nil {
+		t.Errorf("got: %v, want nil", err)
+	}
+}
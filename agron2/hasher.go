@@ -0,0 +1,62 @@
+package agron2
+
+import "github.com/fikryfahrezy/crypt/envelope"
+
+// Argon2DefaultPolicy is the second recommended option of RFC 9106 for
+// memory-constrained environments.
+var Argon2DefaultPolicy = Argon2Policy{
+	Type:    Argon2Id,
+	Version: Argon2Version13,
+	Mcost:   64 * 1024,
+	Tcost:   3,
+	Threads: 4,
+}
+
+// Hasher hashes new passwords with Policy and verifies hashes of every Argon2
+// type with Verifier. Its methods make it a crypt.Hasher.
+type Hasher struct {
+	Policy   Argon2Policy
+	Verifier Verifier // Keys and Envelope default to those of Policy
+}
+
+// ID returns the PHC id of the hashes made with h.
+func (h Hasher) ID() string {
+	return Argon2Type2String(h.Policy.Type, false)
+}
+
+// Hash runs Argon2HashPolicy with h.Policy.
+func (h Hasher) Hash(pwd string) (string, error) {
+	return Argon2HashPolicy(nil, pwd, h.Policy)
+}
+
+func (h Hasher) verifier() Verifier {
+	v := h.Verifier
+	if v.Keys == nil {
+		v.Keys = h.Policy.Keys
+	}
+	if v.Envelope == nil {
+		v.Envelope = h.Policy.Envelope
+	}
+
+	return v
+}
+
+// Aliases returns envelope.ID when hashes are sealed, whose id is not that of
+// the Argon2 hash inside.
+func (h Hasher) Aliases() []string {
+	if h.verifier().Envelope == nil {
+		return nil
+	}
+
+	return []string{envelope.ID}
+}
+
+// Verify runs Verifier.VerifyAny, so it accepts every Argon2 type.
+func (h Hasher) Verify(encoded, pwd string) error {
+	return h.verifier().VerifyAny(background, encoded, pwd)
+}
+
+// NeedsRehash runs NeedsRehash with h.Policy.
+func (h Hasher) NeedsRehash(encoded string) (bool, error) {
+	return NeedsRehash(encoded, h.Policy)
+}
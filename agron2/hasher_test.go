@@ -0,0 +1,48 @@
+package agron2_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/envelope"
+)
+
+func TestHasher(t *testing.T) {
+	h := agron2.Hasher{Policy: testPolicy}
+	if got := h.ID(); got != "argon2id" {
+		t.Errorf("got: %s, want: argon2id", got)
+	}
+	if got := h.Aliases(); len(got) != 0 {
+		t.Errorf("got: %v, want no aliases", got)
+	}
+	if got := (agron2.Hasher{Policy: agron2.Argon2Policy{Envelope: &envelope.Sealer{}}}).Aliases(); len(got) != 1 || got[0] != envelope.ID {
+		t.Errorf("got: %v, want: [%s]", got, envelope.ID)
+	}
+
+	encoded, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if err := h.Verify(encoded, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := h.Verify(encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if needsRehash, err := h.NeedsRehash(encoded); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	// Hashes of other types verify but need a rehash
+	legacy, err := agron2.Argon2Hash("password", "somesalt", 2, 256, 1, 32, agron2.Argon2Version13, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if err := h.Verify(legacy, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if needsRehash, err := h.NeedsRehash(legacy); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true, nil", needsRehash, err)
+	}
+}
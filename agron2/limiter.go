@@ -0,0 +1,237 @@
+package agron2
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Argon2FallbackBudget is the limiter budget in KiB used when the memory
+// available to the process cannot be determined.
+const Argon2FallbackBudget uint64 = 1 << 20
+
+// LimiterStats is a snapshot of a Limiter.
+type LimiterStats struct {
+	Budget   uint64        // memory that may be in use at once (KiB)
+	InUse    uint64        // memory held by running hashes (KiB)
+	Queued   int           // callers waiting for memory
+	Acquired uint64        // number of successful acquisitions
+	Waited   uint64        // number of acquisitions that had to queue
+	WaitTime time.Duration // total time spent queued by successful acquisitions
+	MaxWait  time.Duration // longest time spent queued by a successful acquisition
+}
+
+type waiter struct {
+	kib   uint64
+	ready chan struct{}
+}
+
+// Limiter bounds the Argon2 memory in use at once with a weighted semaphore
+// sized in KiB. Callers queue in FIFO order until enough memory is released
+// or their context is done. The zero Limiter has a budget of
+// DefaultLimiterBudget, taken on first use.
+type Limiter struct {
+	Now func() time.Time // clock used to measure queueing, time.Now if nil
+
+	mu      sync.Mutex
+	budget  uint64
+	inUse   uint64
+	waiters list.List
+	stats   LimiterStats
+}
+
+// NewLimiter returns a Limiter allowing budgetKiB of Argon2 memory at once,
+// or DefaultLimiterBudget if budgetKiB is zero.
+func NewLimiter(budgetKiB uint64) *Limiter {
+	if budgetKiB == 0 {
+		budgetKiB = DefaultLimiterBudget()
+	}
+
+	return &Limiter{budget: budgetKiB}
+}
+
+// DefaultLimiterBudget returns half of the memory available to the process,
+// taken from the cgroup v2 memory.max or /proc/meminfo on Linux, or
+// Argon2FallbackBudget when it cannot be determined.
+func DefaultLimiterBudget() uint64 {
+	if kib, ok := systemMemory(); ok && kib/2 > 0 {
+		return kib / 2
+	}
+
+	return Argon2FallbackBudget
+}
+
+// Acquire blocks until kib of memory is free or ctx is done, in which case it
+// returns ctx.Err(). Requests larger than the whole budget fail immediately.
+func (l *Limiter) Acquire(ctx context.Context, kib uint64) error {
+	now := l.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	l.mu.Lock()
+	l.init()
+	if kib > l.budget {
+		l.mu.Unlock()
+		return newError(Argon2MemoryTooMuch, "Mcost")
+	}
+	if l.waiters.Len() == 0 && l.budget-l.inUse >= kib {
+		l.inUse += kib
+		l.stats.Acquired++
+		l.mu.Unlock()
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+
+	w := &waiter{kib: kib, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(w)
+	l.mu.Unlock()
+
+	start := now()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ready:
+			// Granted while being canceled, give it back
+			l.inUse -= kib
+			l.notify()
+		default:
+			front := l.waiters.Front() == elem
+			l.waiters.Remove(elem)
+			// Callers queued behind a large request may fit now
+			if front {
+				l.notify()
+			}
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+
+	case <-w.ready:
+		waited := now().Sub(start)
+		l.mu.Lock()
+		l.stats.Waited++
+		l.stats.WaitTime += waited
+		if waited > l.stats.MaxWait {
+			l.stats.MaxWait = waited
+		}
+		l.mu.Unlock()
+		return nil
+	}
+}
+
+// init sets the budget of the zero Limiter. l.mu must be held.
+func (l *Limiter) init() {
+	if l.budget == 0 {
+		l.budget = DefaultLimiterBudget()
+	}
+}
+
+// Release returns kib of memory taken by Acquire.
+func (l *Limiter) Release(kib uint64) {
+	l.mu.Lock()
+	if kib > l.inUse {
+		l.mu.Unlock()
+		panic("agron2: released more memory than held")
+	}
+	l.inUse -= kib
+	l.notify()
+	l.mu.Unlock()
+}
+
+// notify wakes the queued callers that fit, in order. l.mu must be held.
+func (l *Limiter) notify() {
+	for {
+		next := l.waiters.Front()
+		if next == nil {
+			return
+		}
+
+		w := next.Value.(*waiter)
+		if l.budget-l.inUse < w.kib {
+			return
+		}
+
+		l.inUse += w.kib
+		l.stats.Acquired++
+		l.waiters.Remove(next)
+		close(w.ready)
+	}
+}
+
+// Stats returns the current state of l.
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	ret := l.stats
+	ret.Budget = l.budget
+	ret.InUse = l.inUse
+	ret.Queued = l.waiters.Len()
+	return ret
+}
+
+// Argon2Ctx runs Argon2CtxContext once the memory of actx is available.
+func (l *Limiter) Argon2Ctx(ctx context.Context, actx Argon2Context, types Argon2Type) (string, error) {
+	kib := argon2Memory(actx)
+	if err := l.Acquire(ctx, kib); err != nil {
+		return "", err
+	}
+	defer l.Release(kib)
+
+	return Argon2CtxContext(ctx, actx, types)
+}
+
+// Argon2Hash runs Argon2HashContext once its memory is available.
+func (l *Limiter) Argon2Hash(ctx context.Context, password, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
+	kib := argon2Memory(Argon2Context{Mcost: memory, Threads: threads})
+	if err := l.Acquire(ctx, kib); err != nil {
+		return "", err
+	}
+	defer l.Release(kib)
+
+	return Argon2HashContext(ctx, password, salt, time, memory, threads, keyLen, version, types)
+}
+
+// Argon2Verify runs Argon2VerifyContext once the memory of encoded is
+// available.
+func (l *Limiter) Argon2Verify(ctx context.Context, encoded, pwd string, types Argon2Type) error {
+	actx, _, err := DecodeString(Argon2Context{}, encoded, types)
+	if err != nil {
+		return err
+	}
+	if err := DefaultDecodeLimits.Check(actx, len(pwd)); err != nil {
+		return err
+	}
+
+	kib := argon2Memory(actx)
+	if err := l.Acquire(ctx, kib); err != nil {
+		return err
+	}
+	defer l.Release(kib)
+
+	return Argon2VerifyContext(ctx, encoded, pwd, types)
+}
+
+// argon2Memory returns the memory in KiB that hashing with actx allocates,
+// Mcost rounded down to whole segments with a minimum of two blocks per
+// segment.
+func argon2Memory(actx Argon2Context) uint64 {
+	threads := uint64(actx.Threads)
+	if threads == 0 {
+		return uint64(actx.Mcost)
+	}
+
+	memory := uint64(actx.Mcost) / (syncPoints * threads) * (syncPoints * threads)
+	if memory < 2*syncPoints*threads {
+		memory = 2 * syncPoints * threads
+	}
+
+	return memory
+}
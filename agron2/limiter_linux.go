@@ -0,0 +1,100 @@
+//go:build linux
+// +build linux
+
+package agron2
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// systemMemory returns the memory limit of the cgroup v2 the process runs in,
+// or the available memory from /proc/meminfo when there is no limit, in KiB.
+func systemMemory() (uint64, bool) {
+	if data, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		if kib, ok := cgroupMemoryMax(cgroupRoot, parseSelfCgroup(data)); ok {
+			return kib, true
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		return parseMeminfo(data)
+	}
+
+	return 0, false
+}
+
+// parseSelfCgroup returns the cgroup v2 path of /proc/self/cgroup, the
+// 0::<path> line, or "/" without one.
+func parseSelfCgroup(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "0::") {
+			return line[len("0::"):]
+		}
+	}
+
+	return "/"
+}
+
+// cgroupMemoryMax returns the lowest memory.max of the cgroup at dir under
+// root and of its ancestors, as each of them bounds the process.
+func cgroupMemoryMax(root, dir string) (uint64, bool) {
+	var ret uint64
+	var found bool
+	for dir = path.Clean("/" + dir); ; dir = path.Dir(dir) {
+		if data, err := os.ReadFile(path.Join(root, dir, "memory.max")); err == nil {
+			if kib, ok := parseCgroupMax(data); ok && (!found || kib < ret) {
+				ret, found = kib, true
+			}
+		}
+		if dir == "/" {
+			return ret, found
+		}
+	}
+}
+
+// parseCgroupMax parses memory.max, which is either a byte count or "max".
+func parseCgroupMax(data []byte) (uint64, bool) {
+	n, err := strconv.ParseUint(string(bytes.TrimSpace(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return n / 1024, true
+}
+
+// parseMeminfo returns MemAvailable, or MemTotal on kernels without it.
+func parseMeminfo(data []byte) (uint64, bool) {
+	var total uint64
+	var hasTotal bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) < 2 {
+			continue
+		}
+
+		n, err := strconv.ParseUint(string(fields[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch string(fields[0]) {
+		case "MemAvailable:":
+			return n, true
+		case "MemTotal:":
+			total, hasTotal = n, true
+		}
+	}
+
+	return total, hasTotal
+}
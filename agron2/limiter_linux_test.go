@@ -0,0 +1,95 @@
+//go:build linux
+// +build linux
+
+package agron2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCgroupMax(t *testing.T) {
+	for _, v := range []struct {
+		data string
+		kib  uint64
+		ok   bool
+	}{
+		{"536870912\n", 524288, true},
+		{"max\n", 0, false},
+		{"", 0, false},
+	} {
+		kib, ok := parseCgroupMax([]byte(v.data))
+		if kib != v.kib || ok != v.ok {
+			t.Errorf("%q - got: %d, %v, want: %d, %v", v.data, kib, ok, v.kib, v.ok)
+		}
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	for _, v := range []struct {
+		data string
+		kib  uint64
+		ok   bool
+	}{
+		{"MemTotal:       16318412 kB\nMemFree:         1208236 kB\nMemAvailable:    9422712 kB\n", 9422712, true},
+		{"MemTotal:       16318412 kB\nMemFree:         1208236 kB\n", 16318412, true},
+		{"", 0, false},
+	} {
+		kib, ok := parseMeminfo([]byte(v.data))
+		if kib != v.kib || ok != v.ok {
+			t.Errorf("%q - got: %d, %v, want: %d, %v", v.data, kib, ok, v.kib, v.ok)
+		}
+	}
+}
+
+func TestParseSelfCgroup(t *testing.T) {
+	for _, v := range []struct {
+		data string
+		want string
+	}{
+		{"0::/system.slice/app.service\n", "/system.slice/app.service"},
+		{"12:memory:/docker/abc\n0::/docker/abc\n", "/docker/abc"},
+		{"4:memory:/legacy\n", "/"},
+		{"", "/"},
+	} {
+		if got := parseSelfCgroup([]byte(v.data)); got != v.want {
+			t.Errorf("%q - got: %q, want: %q", v.data, got, v.want)
+		}
+	}
+}
+
+func TestCgroupMemoryMax(t *testing.T) {
+	root := t.TempDir()
+	for dir, max := range map[string]string{
+		"":          "max\n",
+		"a":         "1073741824\n",
+		"a/b":       "max\n",
+		"a/b/c":     "2147483648\n",
+		"unlimited": "max\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "memory.max"), []byte(max), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, v := range []struct {
+		dir string
+		kib uint64
+		ok  bool
+	}{
+		{"/a/b/c", 1 << 20, true}, // bounded by a
+		{"/a", 1 << 20, true},
+		{"/unlimited", 0, false},
+		{"/", 0, false},
+		{"/missing", 0, false},
+	} {
+		kib, ok := cgroupMemoryMax(root, v.dir)
+		if kib != v.kib || ok != v.ok {
+			t.Errorf("%s - got: %d, %v, want: %d, %v", v.dir, kib, ok, v.kib, v.ok)
+		}
+	}
+}
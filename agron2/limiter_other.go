@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package agron2
+
+func systemMemory() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,177 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func waitQueued(t *testing.T, l *agron2.Limiter, queued int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if l.Stats().Queued == queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("queue depth never reached %d", queued)
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	l := agron2.NewLimiter(100)
+
+	if err := l.Acquire(context.Background(), 60); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+	if err := l.Acquire(context.Background(), 101); !errors.Is(err, agron2.ErrMemoryTooMuch) {
+		t.Errorf("over budget - got: %v, want: %v", err, agron2.ErrMemoryTooMuch)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- l.Acquire(context.Background(), 50)
+	}()
+	waitQueued(t, l, 1)
+
+	if got := l.Stats(); got.InUse != 60 || got.Budget != 100 {
+		t.Errorf("got: %+v, want 60 of 100 in use", got)
+	}
+
+	l.Release(60)
+	if err := <-done; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	got := l.Stats()
+	if got.InUse != 50 || got.Queued != 0 || got.Acquired != 2 || got.Waited != 1 {
+		t.Errorf("got: %+v, want 50 in use after 2 acquisitions, 1 queued", got)
+	}
+	l.Release(50)
+}
+
+func TestLimiterFIFO(t *testing.T) {
+	l := agron2.NewLimiter(100)
+	if err := l.Acquire(context.Background(), 100); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	// A small request must not overtake a large one queued before it
+	large := make(chan error)
+	go func() {
+		large <- l.Acquire(context.Background(), 80)
+	}()
+	waitQueued(t, l, 1)
+
+	small := make(chan error)
+	go func() {
+		small <- l.Acquire(context.Background(), 10)
+	}()
+	waitQueued(t, l, 2)
+
+	l.Release(30)
+	if got := l.Stats().Queued; got != 2 {
+		t.Errorf("queued - got: %d, want: 2", got)
+	}
+
+	l.Release(70)
+	if err := <-large; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+	if err := <-small; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	l := agron2.NewLimiter(100)
+	if err := l.Acquire(context.Background(), 90); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx, 50); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+
+	got := l.Stats()
+	if got.InUse != 90 || got.Queued != 0 {
+		t.Errorf("got: %+v, want 90 in use and nothing queued", got)
+	}
+}
+
+func TestLimiterWaitTime(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := agron2.NewLimiter(64)
+	l.Now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	if err := l.Acquire(context.Background(), 64); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- l.Acquire(context.Background(), 64)
+	}()
+	waitQueued(t, l, 1)
+	l.Release(64)
+	if err := <-done; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	if got := l.Stats(); got.WaitTime != time.Second || got.MaxWait != time.Second {
+		t.Errorf("got: %+v, want 1s of waiting", got)
+	}
+}
+
+func TestLimiterHash(t *testing.T) {
+	l := agron2.NewLimiter(64)
+
+	encoded, err := l.Argon2Hash(context.Background(), "password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	want, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil || encoded != want {
+		t.Errorf("got: %s, %v, want: %s", encoded, err, want)
+	}
+
+	if err := l.Argon2Verify(context.Background(), encoded, "password", agron2.Argon2Id); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	if _, err := l.Argon2Hash(context.Background(), "password", "somesalt", 2, 128, 1, 32, agron2.Argon2Version13, agron2.Argon2Id); !errors.Is(err, agron2.ErrMemoryTooMuch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMemoryTooMuch)
+	}
+
+	if got := l.Stats().InUse; got != 0 {
+		t.Errorf("in use - got: %d, want: 0", got)
+	}
+}
+
+func TestDefaultLimiterBudget(t *testing.T) {
+	if got := agron2.DefaultLimiterBudget(); got == 0 {
+		t.Error("got a zero budget")
+	}
+}
+
+func TestLimiterZero(t *testing.T) {
+	var l agron2.Limiter
+	if got, want := l.Stats().Budget, agron2.DefaultLimiterBudget(); got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := l.Acquire(ctx, 64); err != nil {
+		t.Fatalf("got: %v, want: nil", err)
+	}
+	l.Release(64)
+}
@@ -0,0 +1,106 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+func TestPepperRotation(t *testing.T) {
+	keys := pepper.Static{
+		"k1": []byte("0123456789abcdef"),
+		"k2": []byte("fedcba9876543210"),
+	}
+	ring := &pepper.Keyring{Provider: keys, Current: "k1"}
+	policy := testPolicy
+	policy.Keys = ring
+
+	encoded, err := agron2.Argon2HashPolicy(nil, "password", policy)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !strings.Contains(encoded, ",keyid=azE$") {
+		t.Errorf("missing key id in %s", encoded)
+	}
+
+	v := agron2.Verifier{Keys: ring}
+	if err := v.VerifyAny(context.Background(), encoded, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := v.VerifyAny(context.Background(), encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+
+	// The pepper takes part in the hash
+	wrongKeys := agron2.Verifier{Keys: &pepper.Keyring{Provider: pepper.Static{"k1": keys["k2"]}, Current: "k1"}}
+	if err := wrongKeys.VerifyAny(context.Background(), encoded, "password"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if err := agron2.Argon2VerifyAny(encoded, "password"); !errors.Is(err, agron2.ErrUnknownKey) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrUnknownKey)
+	}
+
+	needsRehash, err := agron2.NeedsRehash(encoded, policy)
+	if err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	// Rotate to k2, k1 hashes still verify but need a rehash
+	ring2 := &pepper.Keyring{Provider: keys, Current: "k2", Retired: []string{"k1"}}
+	policy.Keys = ring2
+	needsRehash, err = agron2.NeedsRehash(encoded, policy)
+	if err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true, nil", needsRehash, err)
+	}
+
+	upgraded, err := agron2.VerifyAndUpgrade(encoded, "password", policy)
+	if err != nil || !strings.Contains(upgraded, ",keyid=azI$") {
+		t.Fatalf("got: %q, %v, want a hash with k2", upgraded, err)
+	}
+	if needsRehash, err = agron2.NeedsRehash(upgraded, policy); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	// Once k1 is dropped, its hashes can no longer be verified
+	v.Keys = &pepper.Keyring{Provider: keys, Current: "k2"}
+	if err := v.VerifyAny(context.Background(), encoded, "password"); !errors.Is(err, agron2.ErrUnknownKey) || !errors.Is(err, pepper.ErrUnknownKey) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrUnknownKey)
+	}
+
+	// An unpeppered hash needs a rehash under a peppered policy
+	plain, err := agron2.Argon2HashPolicy(nil, "password", testPolicy)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if needsRehash, err = agron2.NeedsRehash(plain, policy); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true, nil", needsRehash, err)
+	}
+}
+
+func TestEncodeStringKeyID(t *testing.T) {
+	ctx := agron2.Argon2Context{
+		Version:   agron2.Argon2Version13,
+		Tcost:     2,
+		Mcost:     64,
+		Threads:   1,
+		Secretlen: 32,
+		Pwd:       "password",
+		Salt:      "somesalt",
+		Secret:    []byte("0123456789abcdef"),
+		KeyID:     "k1",
+	}
+	hash, err := agron2.Argon2Ctx(ctx, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	encoded := agron2.EncodeString(ctx, agron2.Argon2Id, hash)
+	decoded, got, err := agron2.DecodeString(agron2.Argon2Context{}, encoded, agron2.Argon2Id)
+	if err != nil || got != hash || decoded.KeyID != "k1" {
+		t.Errorf("got: %q, %v for %s", decoded.KeyID, err, encoded)
+	}
+}
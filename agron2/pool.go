@@ -0,0 +1,196 @@
+package agron2
+
+import (
+	"container/list"
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Future is the result of a hash or verification running in a Pool.
+type Future struct {
+	done    chan struct{}
+	encoded string
+	err     error
+}
+
+// Done is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the result is available and returns it. The encoded hash
+// is empty for verifications.
+func (f *Future) Wait() (string, error) {
+	<-f.done
+	return f.encoded, f.err
+}
+
+type HashResult struct {
+	Encoded string
+	Err     error
+}
+
+type VerifyItem struct {
+	Encoded string
+	Pwd     string
+}
+
+type poolJob struct {
+	ctx context.Context
+	fn  func() (string, error)
+	f   *Future
+}
+
+// Pool runs hashes and verifications on a bounded number of workers, each
+// of which takes the lanes of its hash from a Scheduler before running it.
+// Work waiting for a worker is queued without blocking the caller.
+type Pool struct {
+	Scheduler *Scheduler
+	Class     string // scheduler class of the work, PriorityInteractive if empty
+
+	mu      sync.Mutex
+	queue   list.List
+	workers int
+	running int
+}
+
+// NewPool returns a Pool of workers workers, GOMAXPROCS if zero, sharing the
+// lanes of s, or of NewScheduler(workers) if s is nil.
+func NewPool(workers int, s *Scheduler) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if s == nil {
+		s = NewScheduler(workers)
+	}
+
+	return &Pool{Scheduler: s, workers: workers}
+}
+
+var defaultPool struct {
+	once sync.Once
+	pool *Pool
+}
+
+// DefaultPool returns the Pool used by Argon2HashBatch and Argon2VerifyBatch,
+// NewPool(0, nil) created on first use.
+func DefaultPool() *Pool {
+	defaultPool.once.Do(func() {
+		defaultPool.pool = NewPool(0, nil)
+	})
+
+	return defaultPool.pool
+}
+
+func (p *Pool) class() string {
+	if p.Class == "" {
+		return PriorityInteractive
+	}
+
+	return p.Class
+}
+
+// run queues fn and returns the Future of its result, starting a worker if
+// fewer than p.workers are running.
+func (p *Pool) run(ctx context.Context, fn func() (string, error)) *Future {
+	f := &Future{done: make(chan struct{})}
+
+	p.mu.Lock()
+	p.queue.PushBack(&poolJob{ctx: ctx, fn: fn, f: f})
+	if p.running < p.workers {
+		p.running++
+		go p.work()
+	}
+	p.mu.Unlock()
+
+	return f
+}
+
+// work runs queued jobs until the queue is empty. Jobs whose ctx is done by
+// the time they are taken are not run.
+func (p *Pool) work() {
+	for {
+		p.mu.Lock()
+		front := p.queue.Front()
+		if front == nil {
+			p.running--
+			p.mu.Unlock()
+			return
+		}
+		p.queue.Remove(front)
+		p.mu.Unlock()
+
+		job := front.Value.(*poolJob)
+		if err := job.ctx.Err(); err != nil {
+			job.f.err = err
+		} else {
+			job.f.encoded, job.f.err = job.fn()
+		}
+		close(job.f.done)
+	}
+}
+
+// Hash hashes actx.Pwd with the parameters of actx in the background and
+// returns its encoded string as Argon2Hash does. A hash with more Threads than
+// the class of p may hold runs on the lanes it may hold, with as many
+// goroutines.
+func (p *Pool) Hash(ctx context.Context, actx Argon2Context, types Argon2Type) *Future {
+	return p.run(ctx, func() (string, error) {
+		key, err := p.Scheduler.Argon2Ctx(ctx, p.class(), actx, types)
+		if err != nil {
+			return "", err
+		}
+
+		return EncodeString(actx, types, key), nil
+	})
+}
+
+// Verify runs Argon2Verify in the background like Hash.
+func (p *Pool) Verify(ctx context.Context, encoded, pwd string, types Argon2Type) *Future {
+	return p.run(ctx, func() (string, error) {
+		return "", p.Scheduler.Argon2Verify(ctx, p.class(), encoded, pwd, types)
+	})
+}
+
+// HashBatch hashes every context with Hash and returns the results in the
+// same order.
+func (p *Pool) HashBatch(ctx context.Context, contexts []Argon2Context, types Argon2Type) []HashResult {
+	futures := make([]*Future, len(contexts))
+	for i, actx := range contexts {
+		futures[i] = p.Hash(ctx, actx, types)
+	}
+
+	ret := make([]HashResult, len(futures))
+	for i, f := range futures {
+		ret[i].Encoded, ret[i].Err = f.Wait()
+	}
+
+	return ret
+}
+
+// VerifyBatch verifies every item with Verify and returns the errors in the
+// same order, nil for a match.
+func (p *Pool) VerifyBatch(ctx context.Context, items []VerifyItem, types Argon2Type) []error {
+	futures := make([]*Future, len(items))
+	for i, item := range items {
+		futures[i] = p.Verify(ctx, item.Encoded, item.Pwd, types)
+	}
+
+	ret := make([]error, len(futures))
+	for i, f := range futures {
+		_, ret[i] = f.Wait()
+	}
+
+	return ret
+}
+
+// Argon2HashBatch runs HashBatch on DefaultPool.
+func Argon2HashBatch(ctx context.Context, contexts []Argon2Context, types Argon2Type) []HashResult {
+	return DefaultPool().HashBatch(ctx, contexts, types)
+}
+
+// Argon2VerifyBatch runs VerifyBatch on DefaultPool.
+func Argon2VerifyBatch(ctx context.Context, items []VerifyItem, types Argon2Type) []error {
+	return DefaultPool().VerifyBatch(ctx, items, types)
+}
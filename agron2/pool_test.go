@@ -0,0 +1,193 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func TestHashBatch(t *testing.T) {
+	var contexts []agron2.Argon2Context
+	for i := 0; i < 8; i++ {
+		contexts = append(contexts, agron2.Argon2Context{
+			Pwd:       fmt.Sprintf("password%d", i),
+			Salt:      "somesalt",
+			Secretlen: 32,
+			Mcost:     64,
+			Tcost:     1,
+			Threads:   uint8(i%2 + 1),
+		})
+	}
+	// Invalid on its own, must not fail the others
+	contexts[3].Threads = 0
+
+	p := agron2.NewPool(2, nil)
+	results := p.HashBatch(context.Background(), contexts, agron2.Argon2Id)
+	if len(results) != len(contexts) {
+		t.Fatalf("got %d results, want %d", len(results), len(contexts))
+	}
+
+	var items []agron2.VerifyItem
+	for i, r := range results {
+		if i == 3 {
+			if !errors.Is(r.Err, agron2.ErrThreadsTooFew) {
+				t.Errorf("Test %d - got: %v, want: %v", i, r.Err, agron2.ErrThreadsTooFew)
+			}
+			continue
+		}
+
+		ctx := contexts[i]
+		want, err := agron2.Argon2Hash(ctx.Pwd, ctx.Salt, ctx.Tcost, ctx.Mcost, ctx.Threads, ctx.Secretlen, 0, agron2.Argon2Id)
+		if err != nil || r.Err != nil || r.Encoded != want {
+			t.Errorf("Test %d - got: %s, %v, want: %s, %v", i, r.Encoded, r.Err, want, err)
+		}
+
+		pwd := ctx.Pwd
+		if i == 5 {
+			pwd = "wrong"
+		}
+		items = append(items, agron2.VerifyItem{Encoded: r.Encoded, Pwd: pwd})
+	}
+
+	errs := agron2.Argon2VerifyBatch(context.Background(), items, agron2.Argon2Id)
+	for i, err := range errs {
+		if i == 4 { // contexts[5]
+			if !errors.Is(err, agron2.ErrMismatch) {
+				t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+			}
+		} else if err != nil {
+			t.Errorf("Test %d - got: %v, want: nil", i, err)
+		}
+	}
+}
+
+func TestPoolFuture(t *testing.T) {
+	p := agron2.NewPool(1, nil)
+	actx := agron2.Argon2Context{Pwd: "password", Salt: "somesalt", Secretlen: 32, Mcost: 64, Tcost: 2, Threads: 1}
+
+	f := p.Hash(context.Background(), actx, agron2.Argon2I)
+	<-f.Done()
+	encoded, err := f.Wait()
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	if _, err := p.Verify(context.Background(), encoded, "password", agron2.Argon2I).Wait(); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Hash(ctx, actx, agron2.Argon2I).Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got: %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestPoolWideHash(t *testing.T) {
+	// The background class holds a single lane of 4
+	p := agron2.NewPool(2, agron2.NewScheduler(4))
+	p.Class = agron2.PriorityBackground
+	actx := agron2.Argon2Context{Pwd: "password", Salt: "somesalt", Secretlen: 32, Mcost: 64, Tcost: 1, Threads: 4}
+
+	encoded, err := p.Hash(context.Background(), actx, agron2.Argon2Id).Wait()
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	want, err := agron2.Argon2Hash(actx.Pwd, actx.Salt, actx.Tcost, actx.Mcost, actx.Threads, actx.Secretlen, 0, agron2.Argon2Id)
+	if err != nil || encoded != want {
+		t.Errorf("got: %s, %v, want: %s", encoded, err, want)
+	}
+	if _, err := p.Verify(context.Background(), encoded, "password", agron2.Argon2Id).Wait(); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+}
+
+func TestPoolBoundedGoroutines(t *testing.T) {
+	const workers = 2
+	s := agron2.NewScheduler(1)
+	p := agron2.NewPool(workers, s)
+
+	// Hold the only lane so that the workers queue in the scheduler
+	if err := s.Acquire(context.Background(), agron2.PriorityInteractive, 1, 1); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	contexts := make([]agron2.Argon2Context, 10000)
+	for i := range contexts {
+		contexts[i] = agron2.Argon2Context{Pwd: "password", Salt: "somesalt", Secretlen: 32, Mcost: 8, Tcost: 1, Threads: 1}
+	}
+
+	before := runtime.NumGoroutine()
+	done := make(chan []agron2.HashResult)
+	go func() { done <- p.HashBatch(context.Background(), contexts, agron2.Argon2Id) }()
+
+	for s.Stats()[0].Queued < workers {
+		time.Sleep(time.Millisecond)
+	}
+	// The batch and its workers
+	if got := runtime.NumGoroutine() - before; got > 1+workers {
+		t.Errorf("got %d more goroutines, want at most %d", got, 1+workers)
+	}
+
+	s.Release(agron2.PriorityInteractive, 1)
+	for i, r := range <-done {
+		if r.Err != nil {
+			t.Fatalf("Test %d - error: %v", i, r.Err)
+		}
+	}
+}
+
+func TestPoolHashDoesNotBlock(t *testing.T) {
+	const workers = 2
+	s := agron2.NewScheduler(1)
+	p := agron2.NewPool(workers, s)
+
+	// Hold the only lane so that every worker is busy
+	if err := s.Acquire(context.Background(), agron2.PriorityInteractive, 1, 1); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	actx := agron2.Argon2Context{Pwd: "password", Salt: "somesalt", Secretlen: 32, Mcost: 8, Tcost: 1, Threads: 1}
+	submitted := make(chan []*agron2.Future)
+	go func() {
+		var futures []*agron2.Future
+		for i := 0; i < 4*workers; i++ {
+			futures = append(futures, p.Hash(context.Background(), actx, agron2.Argon2Id))
+		}
+		submitted <- futures
+	}()
+
+	var futures []*agron2.Future
+	select {
+	case futures = <-submitted:
+	case <-time.After(time.Second):
+		t.Fatalf("Hash blocked while the workers were busy")
+	}
+	for i, f := range futures {
+		select {
+		case <-f.Done():
+			t.Errorf("Test %d - done while the lane is held", i)
+		default:
+		}
+	}
+
+	// Canceled while queued, never run
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := p.Hash(ctx, actx, agron2.Argon2Id)
+	cancel()
+
+	s.Release(agron2.PriorityInteractive, 1)
+	for i, f := range futures {
+		if _, err := f.Wait(); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+	}
+	if _, err := canceled.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got: %v, want: %v", err, context.Canceled)
+	}
+}
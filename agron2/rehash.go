@@ -0,0 +1,65 @@
+package agron2
+
+// NeedsRehash reports whether encoded was computed with lower m/t/p than
+// policy, or with a type, version, salt length, key length, encoding or pepper
+// key other than the one in policy, such as a retired key. Hashes costlier
+// than policy are kept, so that VerifyAndUpgrade never weakens them. A hash
+// sealed with policy.Envelope is opened first, whether it needs to be sealed
+// again is up to Sealer.NeedsReseal.
+func NeedsRehash(encoded string, policy Argon2Policy) (bool, error) {
+	encoded, err := Verifier{Envelope: policy.Envelope}.open(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	types, err := decodeType(encoded, nil)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, _, err := DecodeString(Argon2Context{}, encoded, types)
+	if err != nil {
+		return false, err
+	}
+
+	want := policy.Context()
+	if want.Version == 0 {
+		want.Version = Argon2VersionNumber
+	}
+	saltLen := policy.SaltLen
+	if saltLen == 0 {
+		saltLen = Argon2DefaultSaltLength
+	}
+	retired := ctx.KeyID != ""
+	if policy.Keys != nil {
+		retired = policy.Keys.IsRetired(ctx.KeyID)
+	}
+
+	ret := types != policy.Type ||
+		ctx.Version != want.Version ||
+		ctx.Mcost < want.Mcost ||
+		ctx.Tcost < want.Tcost ||
+		ctx.Threads < want.Threads ||
+		uint32(len(ctx.Salt)) != saltLen ||
+		ctx.Secretlen != want.Secretlen ||
+		ctx.Encoding != Argon2EncodingBase64 ||
+		retired
+	return ret, nil
+}
+
+// VerifyAndUpgrade verifies pwd against encoded, whose type is taken from the
+// string itself. When the password matches but encoded is outdated according
+// to NeedsRehash, it returns a new hash computed with policy, otherwise it
+// returns an empty string.
+func VerifyAndUpgrade(encoded, pwd string, policy Argon2Policy) (string, error) {
+	if err := (Verifier{Keys: policy.Keys, Envelope: policy.Envelope}).VerifyAny(background, encoded, pwd); err != nil {
+		return "", err
+	}
+
+	needsRehash, err := NeedsRehash(encoded, policy)
+	if err != nil || !needsRehash {
+		return "", err
+	}
+
+	return Argon2HashPolicy(nil, pwd, policy)
+}
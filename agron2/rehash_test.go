@@ -0,0 +1,119 @@
+package agron2_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+var testPolicy = agron2.Argon2Policy{
+	Type:    agron2.Argon2Id,
+	Version: agron2.Argon2Version13,
+	Mcost:   256,
+	Tcost:   2,
+	Threads: 1,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+func TestNeedsRehash(t *testing.T) {
+	salt := "0123456789abcdef"
+	current, err := agron2.Argon2Hash("password", salt, 2, 256, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	needsRehash, err := agron2.NeedsRehash(current, testPolicy)
+	if err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	outdated := []agron2.Argon2Policy{testPolicy, testPolicy, testPolicy, testPolicy, testPolicy, testPolicy, testPolicy}
+	outdated[0].Type = agron2.Argon2I
+	outdated[1].Version = agron2.Argon2Version10
+	outdated[2].Mcost = 512
+	outdated[3].Tcost = 3
+	outdated[4].Threads = 2
+	outdated[5].SaltLen = 32
+	outdated[6].KeyLen = 64
+	for i, policy := range outdated {
+		needsRehash, err := agron2.NeedsRehash(current, policy)
+		if err != nil || !needsRehash {
+			t.Errorf("Policy %d - got: %v, %v, want: true, nil", i, needsRehash, err)
+		}
+	}
+
+	// Hashes costlier than the policy are kept
+	weaker := []agron2.Argon2Policy{testPolicy, testPolicy, testPolicy}
+	weaker[0].Mcost = 128
+	weaker[1].Tcost = 1
+	weaker[2].Mcost, weaker[2].Tcost = 64, 1
+	for i, policy := range weaker {
+		needsRehash, err := agron2.NeedsRehash(current, policy)
+		if err != nil || needsRehash {
+			t.Errorf("Weaker policy %d - got: %v, %v, want: false, nil", i, needsRehash, err)
+		}
+	}
+
+	ctx := testPolicy.Context()
+	ctx.Pwd, ctx.Salt, ctx.Encoding = "password", salt, agron2.Argon2EncodingHex
+	key, err := agron2.Argon2Ctx(ctx, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	needsRehash, err = agron2.NeedsRehash(agron2.EncodeString(ctx, agron2.Argon2Id, key), testPolicy)
+	if err != nil || !needsRehash {
+		t.Errorf("hex encoding - got: %v, %v, want: true, nil", needsRehash, err)
+	}
+
+	if _, err := agron2.NeedsRehash("$bcrypt$x", testPolicy); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+}
+
+func TestVerifyAndUpgrade(t *testing.T) {
+	old, err := agron2.Argon2Hash("password", "somesalt", 1, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	if _, err := agron2.VerifyAndUpgrade(old, "wrong", testPolicy); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+
+	upgraded, err := agron2.VerifyAndUpgrade(old, "password", testPolicy)
+	if err != nil || upgraded == "" {
+		t.Fatalf("got: %q, %v, want upgraded hash", upgraded, err)
+	}
+	if err = agron2.Argon2Verify(upgraded, "password", agron2.Argon2Id); err != nil {
+		t.Errorf("failed to verify upgraded hash: %v", err)
+	}
+
+	needsRehash, err := agron2.NeedsRehash(upgraded, testPolicy)
+	if err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	again, err := agron2.VerifyAndUpgrade(upgraded, "password", testPolicy)
+	if err != nil || again != "" {
+		t.Errorf("got: %q, %v, want no upgrade", again, err)
+	}
+}
+
+func TestVerifyAndUpgradeStronger(t *testing.T) {
+	// m=512, t=3, p=2 against the m=256, t=2, p=1 of testPolicy
+	stronger, err := agron2.Argon2Hash("password", "0123456789abcdef", 3, 512, 2, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	needsRehash, err := agron2.NeedsRehash(stronger, testPolicy)
+	if err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+	upgraded, err := agron2.VerifyAndUpgrade(stronger, "password", testPolicy)
+	if err != nil || upgraded != "" {
+		t.Errorf("got: %q, %v, want no downgrade", upgraded, err)
+	}
+}
@@ -0,0 +1,60 @@
+package agron2
+
+import (
+	"io"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+)
+
+// Argon2MinRandomSaltLength is the shortest salt in bytes accepted by
+// Argon2GenerateSalt and Argon2HashPolicy, stricter than Argon2MinSaltLength.
+const Argon2MinRandomSaltLength = kdf.MinRandomSaltLength
+
+// Argon2GenerateSalt reads a salt of length bytes from r, or from crypto/rand
+// when r is nil, and checks it with Argon2CheckSalt.
+func Argon2GenerateSalt(r io.Reader, length uint32) (string, error) {
+	return kdf.GenerateSalt(r, length)
+}
+
+// Argon2CheckSalt rejects salts shorter than Argon2MinRandomSaltLength and
+// salts with so few distinct bytes that they cannot come from a working
+// random source, see kdf.CheckSalt.
+func Argon2CheckSalt(salt string) error {
+	return kdf.CheckSalt(salt)
+}
+
+// Argon2HashPolicy hashes password with the parameters of policy and a fresh
+// salt of policy.SaltLen bytes read from r, or from crypto/rand when r is nil.
+func Argon2HashPolicy(r io.Reader, password string, policy Argon2Policy) (string, error) {
+	saltLen := policy.SaltLen
+	if saltLen == 0 {
+		saltLen = Argon2DefaultSaltLength
+	}
+
+	salt, err := Argon2GenerateSalt(r, saltLen)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := policy.Context()
+	ctx.Pwd = password
+	ctx.Salt = salt
+	if policy.Keys != nil {
+		if ctx.KeyID, ctx.Secret, err = policy.Keys.CurrentKey(); err != nil {
+			return "", err
+		}
+		defer wipe(ctx.Secret)
+	}
+
+	key, err := Argon2Ctx(ctx, policy.Type)
+	if err != nil {
+		return "", err
+	}
+
+	ret := EncodeString(ctx, policy.Type, key)
+	if policy.Envelope != nil {
+		return policy.Envelope.Seal(ret)
+	}
+
+	return ret, nil
+}
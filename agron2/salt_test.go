@@ -0,0 +1,89 @@
+package agron2_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func countingReader() io.Reader {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return bytes.NewReader(b)
+}
+
+func TestArgon2GenerateSalt(t *testing.T) {
+	salt, err := agron2.Argon2GenerateSalt(countingReader(), 16)
+	if err != nil {
+		t.Fatalf("failed to generate salt: %v", err)
+	}
+	if want := "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f"; salt != want {
+		t.Errorf("got: %x, want: %x", salt, want)
+	}
+
+	random, err := agron2.Argon2GenerateSalt(nil, agron2.Argon2DefaultSaltLength)
+	if err != nil || uint32(len(random)) != agron2.Argon2DefaultSaltLength {
+		t.Errorf("got: %x, %v", random, err)
+	}
+
+	if _, err := agron2.Argon2GenerateSalt(countingReader(), 8); !errors.Is(err, agron2.ErrSaltTooShort) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrSaltTooShort)
+	}
+
+	zeros := bytes.NewReader(make([]byte, 16))
+	if _, err := agron2.Argon2GenerateSalt(zeros, 16); !errors.Is(err, agron2.ErrSaltLowEntropy) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrSaltLowEntropy)
+	}
+
+	if _, err := agron2.Argon2GenerateSalt(bytes.NewReader(nil), 16); !errors.Is(err, io.EOF) {
+		t.Errorf("got: %v, want: %v", err, io.EOF)
+	}
+}
+
+func TestArgon2CheckSalt(t *testing.T) {
+	for i, salt := range []string{"somesalt", "abababababababab", "0000000000000000"} {
+		if err := agron2.Argon2CheckSalt(salt); err == nil {
+			t.Errorf("Test %d - expected error for %q", i, salt)
+		}
+	}
+
+	if err := agron2.Argon2CheckSalt("0123456789abcdef"); err != nil {
+		t.Errorf("got: %v, want nil", err)
+	}
+}
+
+func TestArgon2HashPolicy(t *testing.T) {
+	policy := agron2.Argon2Policy{Type: agron2.Argon2Id, Mcost: 64, Tcost: 1, Threads: 1}
+
+	encoded, err := agron2.Argon2HashPolicy(countingReader(), "password", policy)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if want := "$argon2id$v=19$m=64,t=1,p=1$AAECAwQFBgcICQoLDA0ODw$"; encoded[:len(want)] != want {
+		t.Errorf("got: %s, want prefix: %s", encoded, want)
+	}
+
+	again, err := agron2.Argon2HashPolicy(countingReader(), "password", policy)
+	if err != nil || again != encoded {
+		t.Errorf("got: %s, %v, want: %s", again, err, encoded)
+	}
+
+	if err = agron2.Argon2Verify(encoded, "password", agron2.Argon2Id); err != nil {
+		t.Errorf("failed to verify: %v", err)
+	}
+
+	random, err := agron2.Argon2HashPolicy(nil, "password", policy)
+	if err != nil || random == encoded {
+		t.Errorf("got: %s, %v, want a different random salt", random, err)
+	}
+
+	policy.SaltLen = 8
+	if _, err := agron2.Argon2HashPolicy(nil, "password", policy); !errors.Is(err, agron2.ErrSaltTooShort) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrSaltTooShort)
+	}
+}
@@ -0,0 +1,328 @@
+package agron2
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Names of the classes a Scheduler has by default.
+const (
+	PriorityInteractive = "interactive"
+	PriorityBackground  = "background"
+)
+
+var ErrUnknownClass = errors.New("unknown priority class")
+
+// SchedulerClass is a named priority class of a Scheduler.
+type SchedulerClass struct {
+	Name     string
+	Weight   uint // share of the lanes relative to the other classes, 1 if zero
+	MaxLanes int  // lanes the class may use at once, zero means no cap
+}
+
+// SchedulerClassStats is a snapshot of a class of a Scheduler.
+type SchedulerClassStats struct {
+	Name     string
+	Queued   int           // callers waiting for lanes
+	Running  int           // lanes held by the class
+	Acquired uint64        // number of successful acquisitions
+	WaitTime time.Duration // total time spent queued by successful acquisitions
+}
+
+type schedRequest struct {
+	lanes   int
+	start   float64 // virtual start tag
+	granted bool
+	ready   chan struct{}
+}
+
+type schedClass struct {
+	SchedulerClass
+	queue  list.List
+	finish float64 // virtual finish tag of the last request
+	stats  SchedulerClassStats
+}
+
+// Scheduler shares a number of lanes, the Argon2 threads running at once,
+// between priority classes with start-time fair queuing: each class gets
+// lanes in proportion to its weight while it has work queued, and no more than
+// its MaxLanes. Requests of a class are served in FIFO order.
+type Scheduler struct {
+	Limiter *Limiter         // memory limiter taken after the lanes, none if nil
+	Now     func() time.Time // clock used to measure queueing, time.Now if nil
+
+	mu      sync.Mutex
+	lanes   int
+	inUse   int
+	vtime   float64
+	classes []*schedClass
+}
+
+// NewScheduler returns a Scheduler of lanes lanes, GOMAXPROCS if zero, with
+// the given classes. Without classes it has PriorityInteractive with weight 4
+// and PriorityBackground with weight 1, capped at a quarter of the lanes.
+func NewScheduler(lanes int, classes ...SchedulerClass) *Scheduler {
+	if lanes <= 0 {
+		lanes = runtime.GOMAXPROCS(0)
+	}
+
+	if len(classes) == 0 {
+		background := lanes / 4
+		if background < 1 {
+			background = 1
+		}
+		classes = []SchedulerClass{
+			{Name: PriorityInteractive, Weight: 4},
+			{Name: PriorityBackground, Weight: 1, MaxLanes: background},
+		}
+	}
+
+	s := &Scheduler{lanes: lanes}
+	for _, c := range classes {
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+		sc := &schedClass{SchedulerClass: c}
+		sc.stats.Name = c.Name
+		s.classes = append(s.classes, sc)
+	}
+
+	return s
+}
+
+func (s *Scheduler) class(name string) *schedClass {
+	for _, c := range s.classes {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Acquire blocks until lanes lanes are granted to class or ctx is done, in
+// which case it returns ctx.Err(). cost is the amount of work the lanes are
+// held for, such as memory times passes, and is charged to the class.
+func (s *Scheduler) Acquire(ctx context.Context, class string, lanes int, cost uint64) error {
+	now := s.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	s.mu.Lock()
+	c := s.class(class)
+	if c == nil {
+		s.mu.Unlock()
+		return ErrUnknownClass
+	}
+	if lanes < 1 {
+		s.mu.Unlock()
+		return newError(Argon2ThreadsTooFew, "Threads")
+	}
+	if lanes > s.lanes || (c.MaxLanes > 0 && lanes > c.MaxLanes) {
+		s.mu.Unlock()
+		return newError(Argon2ThreadsTooMany, "Threads")
+	}
+
+	r := &schedRequest{lanes: lanes, ready: make(chan struct{})}
+	r.start = s.vtime
+	if c.finish > r.start {
+		r.start = c.finish
+	}
+	c.finish = r.start + float64(cost)/float64(c.Weight)
+	elem := c.queue.PushBack(r)
+	s.dispatch()
+	if r.granted {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	start := now()
+	select {
+	case <-ctx.Done():
+		s.mu.Lock()
+		if r.granted {
+			s.release(c, lanes)
+		} else {
+			c.queue.Remove(elem)
+			s.dispatch()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+
+	case <-r.ready:
+		waited := now().Sub(start)
+		s.mu.Lock()
+		c.stats.WaitTime += waited
+		s.mu.Unlock()
+		return nil
+	}
+}
+
+// Release returns lanes lanes taken by Acquire for class.
+func (s *Scheduler) Release(class string, lanes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.class(class)
+	if c == nil || lanes > c.stats.Running {
+		panic("agron2: released more lanes than held")
+	}
+	s.release(c, lanes)
+}
+
+// fit returns the lanes of actx narrowed to what class may hold, and ctx
+// running no more goroutines than that, so that the lanes charged are the
+// CPUs used. The hash itself, its Threads included, is unchanged.
+func (s *Scheduler) fit(ctx context.Context, class string, actx Argon2Context) (context.Context, int) {
+	lanes := int(actx.Threads)
+	if lanes > s.lanes {
+		lanes = s.lanes
+	}
+	if c := s.class(class); c != nil && c.MaxLanes > 0 && lanes > c.MaxLanes {
+		lanes = c.MaxLanes
+	}
+	if lanes < int(actx.Threads) {
+		ctx = withWorkers(ctx, lanes)
+	}
+
+	return ctx, lanes
+}
+
+// release gives back lanes of c and dispatches. s.mu must be held.
+func (s *Scheduler) release(c *schedClass, lanes int) {
+	s.inUse -= lanes
+	c.stats.Running -= lanes
+	s.dispatch()
+}
+
+// dispatch grants lanes to queued requests in order of their start tags,
+// skipping classes at their cap. It stops at the first request that does not
+// fit so that wide requests are not starved by narrow ones. s.mu must be held.
+func (s *Scheduler) dispatch() {
+	for {
+		var best *schedClass
+		var next *list.Element
+		for _, c := range s.classes {
+			front := c.queue.Front()
+			if front == nil {
+				continue
+			}
+
+			r := front.Value.(*schedRequest)
+			if c.MaxLanes > 0 && c.stats.Running+r.lanes > c.MaxLanes {
+				continue
+			}
+			if next == nil || r.start < next.Value.(*schedRequest).start {
+				best, next = c, front
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		r := next.Value.(*schedRequest)
+		if s.inUse+r.lanes > s.lanes {
+			return
+		}
+
+		best.queue.Remove(next)
+		s.inUse += r.lanes
+		best.stats.Running += r.lanes
+		best.stats.Acquired++
+		if r.start > s.vtime {
+			s.vtime = r.start
+		}
+		r.granted = true
+		close(r.ready)
+	}
+}
+
+// Stats returns the state of every class in the order they were given.
+func (s *Scheduler) Stats() []SchedulerClassStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := make([]SchedulerClassStats, len(s.classes))
+	for i, c := range s.classes {
+		ret[i] = c.stats
+		ret[i].Queued = c.queue.Len()
+	}
+
+	return ret
+}
+
+// acquire takes the lanes of actx narrowed by fit and, with a Limiter, its
+// memory. It returns the context to hash under and the function that gives
+// them back.
+func (s *Scheduler) acquire(ctx context.Context, class string, actx Argon2Context) (context.Context, func(), error) {
+	kib := argon2Memory(actx)
+	ctx, lanes := s.fit(ctx, class, actx)
+	if err := s.Acquire(ctx, class, lanes, kib*uint64(actx.Tcost)); err != nil {
+		return nil, nil, err
+	}
+
+	if s.Limiter != nil {
+		if err := s.Limiter.Acquire(ctx, kib); err != nil {
+			s.Release(class, lanes)
+			return nil, nil, err
+		}
+	}
+
+	return ctx, func() {
+		if s.Limiter != nil {
+			s.Limiter.Release(kib)
+		}
+		s.Release(class, lanes)
+	}, nil
+}
+
+// Argon2Ctx runs Argon2CtxContext once class is granted the lanes of actx. A
+// hash with more Threads than class may hold runs on the lanes it may hold,
+// with as many goroutines.
+func (s *Scheduler) Argon2Ctx(ctx context.Context, class string, actx Argon2Context, types Argon2Type) (string, error) {
+	ctx, release, err := s.acquire(ctx, class, actx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return Argon2CtxContext(ctx, actx, types)
+}
+
+// Argon2Hash runs Argon2HashContext once class is granted its lanes, see
+// Argon2Ctx.
+func (s *Scheduler) Argon2Hash(ctx context.Context, class string, password, salt string, time, memory uint32, threads uint8, keyLen uint32, version int, types Argon2Type) (string, error) {
+	ctx, release, err := s.acquire(ctx, class, Argon2Context{Mcost: memory, Tcost: time, Threads: threads})
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return Argon2HashContext(ctx, password, salt, time, memory, threads, keyLen, version, types)
+}
+
+// Argon2Verify runs Argon2VerifyContext once class is granted the lanes of
+// encoded, see Argon2Ctx.
+func (s *Scheduler) Argon2Verify(ctx context.Context, class string, encoded, pwd string, types Argon2Type) error {
+	actx, _, err := DecodeString(Argon2Context{}, encoded, types)
+	if err != nil {
+		return err
+	}
+	if err := DefaultDecodeLimits.Check(actx, len(pwd)); err != nil {
+		return err
+	}
+
+	ctx, release, err := s.acquire(ctx, class, actx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return Argon2VerifyContext(ctx, encoded, pwd, types)
+}
@@ -0,0 +1,152 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func waitScheduled(t *testing.T, s *agron2.Scheduler, queued int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		total := 0
+		for _, c := range s.Stats() {
+			total += c.Queued
+		}
+		if total == queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("queue depth never reached %d", queued)
+}
+
+func TestSchedulerWeightedFairQueuing(t *testing.T) {
+	s := agron2.NewScheduler(1,
+		agron2.SchedulerClass{Name: "a", Weight: 3},
+		agron2.SchedulerClass{Name: "b", Weight: 1},
+	)
+	if err := s.Acquire(context.Background(), "a", 1, 1); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	granted := make(chan string)
+	for i := 0; i < 4; i++ {
+		for _, class := range []string{"a", "b"} {
+			class := class
+			go func() {
+				if err := s.Acquire(context.Background(), class, 1, 1); err != nil {
+					t.Errorf("failed to acquire: %v", err)
+				}
+				granted <- class
+			}()
+		}
+	}
+	waitScheduled(t, s, 8)
+
+	var order []string
+	last := "a"
+	for i := 0; i < 8; i++ {
+		s.Release(last, 1)
+		last = <-granted
+		order = append(order, last)
+	}
+	s.Release(last, 1)
+
+	if got, want := strings.Join(order, ""), "baaababb"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+func TestSchedulerMaxLanes(t *testing.T) {
+	s := agron2.NewScheduler(4)
+
+	if err := s.Acquire(context.Background(), agron2.PriorityBackground, 1, 1); err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, agron2.PriorityBackground, 1, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("background over its cap - got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+
+	if err := s.Acquire(context.Background(), agron2.PriorityInteractive, 3, 1); err != nil {
+		t.Errorf("interactive - got: %v, want: nil", err)
+	}
+
+	if err := s.Acquire(context.Background(), agron2.PriorityBackground, 2, 1); !errors.Is(err, agron2.ErrThreadsTooMany) {
+		t.Errorf("wider than the cap - got: %v, want: %v", err, agron2.ErrThreadsTooMany)
+	}
+	if err := s.Acquire(context.Background(), "bulk", 1, 1); !errors.Is(err, agron2.ErrUnknownClass) {
+		t.Errorf("unknown class - got: %v, want: %v", err, agron2.ErrUnknownClass)
+	}
+
+	stats := s.Stats()
+	if stats[0].Running != 3 || stats[1].Running != 1 || stats[1].Queued != 0 {
+		t.Errorf("got: %+v", stats)
+	}
+}
+
+func TestSchedulerHash(t *testing.T) {
+	s := agron2.NewScheduler(2)
+	s.Limiter = agron2.NewLimiter(64)
+
+	encoded, err := s.Argon2Hash(context.Background(), agron2.PriorityBackground, "password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	want, err := agron2.Argon2Hash("password", "somesalt", 2, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil || encoded != want {
+		t.Errorf("got: %s, %v, want: %s", encoded, err, want)
+	}
+
+	if err := s.Argon2Verify(context.Background(), agron2.PriorityInteractive, encoded, "password", agron2.Argon2Id); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	if got := s.Limiter.Stats().InUse; got != 0 {
+		t.Errorf("memory in use - got: %d, want: 0", got)
+	}
+	for _, c := range s.Stats() {
+		if c.Running != 0 || c.Acquired != 1 {
+			t.Errorf("got: %+v, want 1 acquisition and nothing running", c)
+		}
+	}
+}
+
+func TestSchedulerWideHash(t *testing.T) {
+	// The background class holds a single lane of 4, the policy hashes on 4
+	s := agron2.NewScheduler(4)
+	policy := agron2.Argon2DefaultPolicy
+	actx := policy.Context()
+	actx.Salt = "somesaltsomesalt"
+
+	encoded, err := s.Argon2Hash(context.Background(), agron2.PriorityBackground, "password", actx.Salt, actx.Tcost, actx.Mcost, actx.Threads, actx.Secretlen, actx.Version, policy.Type)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	want, err := agron2.Argon2Hash("password", actx.Salt, actx.Tcost, actx.Mcost, actx.Threads, actx.Secretlen, actx.Version, policy.Type)
+	if err != nil || encoded != want {
+		t.Errorf("got: %s, %v, want: %s", encoded, err, want)
+	}
+
+	if err := s.Argon2Verify(context.Background(), agron2.PriorityBackground, encoded, "password", policy.Type); err != nil {
+		t.Errorf("verify - got: %v, want: nil", err)
+	}
+
+	actx.Pwd = "password"
+	key, err := s.Argon2Ctx(context.Background(), agron2.PriorityBackground, actx, policy.Type)
+	if err != nil || agron2.EncodeString(actx, policy.Type, key) != want {
+		t.Errorf("ctx - got: %v, want: %s", err, want)
+	}
+
+	if stats := s.Stats(); stats[1].Acquired != 3 || stats[1].Running != 0 {
+		t.Errorf("got: %+v", stats[1])
+	}
+}
@@ -0,0 +1,296 @@
+package agron2
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/envelope"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+// DecodeLimits bounds the parameters of an encoded hash that a Verifier
+// accepts, so that a tampered or hostile string cannot make it allocate or
+// compute without end. A zero field takes its value from DefaultDecodeLimits.
+type DecodeLimits struct {
+	MaxMemory  uint32 // largest m= (KiB)
+	MaxTime    uint32 // largest t=
+	MaxThreads uint8  // largest p=
+	MaxSaltLen uint32 // longest salt in bytes
+	MaxHashLen uint32 // longest hash in bytes
+	MaxPwdLen  uint32 // longest password in bytes
+}
+
+// DefaultDecodeLimits are the limits of Argon2Verify and its variants.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxMemory:  1 << 21, // 2 GiB
+	MaxTime:    64,
+	MaxThreads: 64,
+	MaxSaltLen: 128,
+	MaxHashLen: 128,
+	MaxPwdLen:  4096,
+}
+
+// WithDefaults returns l with its zero fields set from DefaultDecodeLimits.
+func (l DecodeLimits) WithDefaults() DecodeLimits {
+	if l.MaxMemory == 0 {
+		l.MaxMemory = DefaultDecodeLimits.MaxMemory
+	}
+	if l.MaxTime == 0 {
+		l.MaxTime = DefaultDecodeLimits.MaxTime
+	}
+	if l.MaxThreads == 0 {
+		l.MaxThreads = DefaultDecodeLimits.MaxThreads
+	}
+	if l.MaxSaltLen == 0 {
+		l.MaxSaltLen = DefaultDecodeLimits.MaxSaltLen
+	}
+	if l.MaxHashLen == 0 {
+		l.MaxHashLen = DefaultDecodeLimits.MaxHashLen
+	}
+	if l.MaxPwdLen == 0 {
+		l.MaxPwdLen = DefaultDecodeLimits.MaxPwdLen
+	}
+
+	return l
+}
+
+// maxEncodedLength returns an upper bound of the length of an encoded string
+// within l: hex salt and hash plus room for the id and parameters.
+func (l DecodeLimits) maxEncodedLength() uint64 {
+	return 2*(uint64(l.MaxSaltLen)+uint64(l.MaxHashLen)) + 1024
+}
+
+// Check reports the first parameter of actx, as returned by DecodeString, or
+// the password length that exceeds l.
+func (l DecodeLimits) Check(actx Argon2Context, pwdLen int) error {
+	l = l.WithDefaults()
+
+	switch {
+	case int64(pwdLen) > int64(l.MaxPwdLen):
+		return newError(Argon2PwdTooLong, "Pwd")
+	case actx.Mcost > l.MaxMemory:
+		return newError(Argon2MemoryTooMuch, "m")
+	case actx.Tcost > l.MaxTime:
+		return newError(Argon2TimeTooLarge, "t")
+	case actx.Threads > l.MaxThreads:
+		return newError(Argon2ThreadsTooMany, "p")
+	case uint64(len(actx.Salt)) > uint64(l.MaxSaltLen):
+		return newError(Argon2SaltTooLong, "salt")
+	case actx.Secretlen > l.MaxHashLen:
+		return newError(Argon2SecretTooLong, "hash")
+	}
+
+	return nil
+}
+
+// StrengthFloor is the weakest hash a Verifier accepts. A zero field is not
+// checked, so the zero StrengthFloor accepts every hash.
+type StrengthFloor struct {
+	Types      []Argon2Type // accepted types, any type if empty
+	MinVersion int          // lowest version
+	MinMemory  uint32       // smallest m= (KiB)
+	MinTime    uint32       // smallest t=
+	MinSaltLen uint32       // shortest salt in bytes
+	MinHashLen uint32       // shortest hash in bytes
+
+	// Until LegacyUntil, hashes below the floor are still verified so that
+	// they can be upgraded on login during a migration.
+	LegacyUntil time.Time
+}
+
+// Check reports the first parameter of actx, as returned by DecodeString for
+// types, that is below f with an ErrTooWeak error.
+func (f StrengthFloor) Check(actx Argon2Context, types Argon2Type) error {
+	if len(f.Types) != 0 {
+		found := false
+		for _, t := range f.Types {
+			found = found || t == types
+		}
+		if !found {
+			return newError(Argon2TooWeak, "id")
+		}
+	}
+
+	switch {
+	case actx.Version < f.MinVersion:
+		return newError(Argon2TooWeak, "v")
+	case actx.Mcost < f.MinMemory:
+		return newError(Argon2TooWeak, "m")
+	case actx.Tcost < f.MinTime:
+		return newError(Argon2TooWeak, "t")
+	case uint32(len(actx.Salt)) < f.MinSaltLen:
+		return newError(Argon2TooWeak, "salt")
+	case actx.Secretlen < f.MinHashLen:
+		return newError(Argon2TooWeak, "hash")
+	}
+
+	return nil
+}
+
+// Verifier verifies passwords against encoded hashes that may come from
+// untrusted storage or input. Its zero value uses DefaultDecodeLimits and
+// has no strength floor.
+type Verifier struct {
+	Limits DecodeLimits
+	Floor  StrengthFloor
+	Keys   *pepper.Keyring // peppers of hashes with a keyid, which fail without it
+
+	// Envelope opens sealed hashes before verifying them, which fail
+	// without it.
+	Envelope *envelope.Sealer
+	Now      func() time.Time // clock compared with Floor.LegacyUntil, time.Now if nil
+
+	// MinDuration pads every verification to take at least this long,
+	// evening out the timing of success, mismatch and early failures.
+	MinDuration time.Duration
+}
+
+func (v Verifier) now() time.Time {
+	if v.Now == nil {
+		return time.Now()
+	}
+
+	return v.Now()
+}
+
+// open returns encoded, opened with v.Envelope if it is sealed.
+func (v Verifier) open(encoded string) (string, error) {
+	if !envelope.IsSealed(encoded) {
+		return encoded, nil
+	}
+	if v.Envelope == nil {
+		return "", newError(Argon2DecodingFail, envelope.ID)
+	}
+
+	ret, err := v.Envelope.Open(encoded)
+	if err != nil {
+		return "", decodingError(envelope.ID, err)
+	}
+
+	return ret, nil
+}
+
+// pad sleeps until v.MinDuration has passed since start or ctx is done.
+func (v Verifier) pad(ctx context.Context, start time.Time) {
+	remaining := v.MinDuration - v.now().Sub(start)
+	if remaining <= 0 {
+		return
+	}
+
+	t := time.NewTimer(remaining)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// Verify is like Argon2VerifyContext but enforces v.Limits and, unless the
+// migration window is open, v.Floor before hashing. A hash below the floor
+// fails with ErrTooWeak whether the password matches or not.
+func (v Verifier) Verify(ctx context.Context, encoded, pwd string, types Argon2Type) error {
+	pwdBytes := []byte(pwd)
+	defer wipe(pwdBytes)
+
+	return v.VerifyBytes(ctx, encoded, pwdBytes, types)
+}
+
+// VerifyAny is like Verify but takes the type from the encoded string, see
+// Argon2VerifyAny.
+func (v Verifier) VerifyAny(ctx context.Context, encoded, pwd string, allowed ...Argon2Type) error {
+	start := v.now()
+	encoded, err := v.open(encoded)
+	var types Argon2Type
+	if err == nil {
+		types, err = decodeType(encoded, allowed)
+	}
+	if err == nil {
+		pwdBytes := []byte(pwd)
+		defer wipe(pwdBytes)
+
+		err = v.verifyBytes(ctx, encoded, pwdBytes, types, true)
+	}
+	v.pad(ctx, start)
+
+	return err
+}
+
+// VerifyBytes is like Verify with the password in a byte slice, which is left
+// untouched.
+func (v Verifier) VerifyBytes(ctx context.Context, encoded string, pwd []byte, types Argon2Type) error {
+	start := v.now()
+	err := v.verifyBytes(ctx, encoded, pwd, types, true)
+	v.pad(ctx, start)
+
+	return err
+}
+
+// verifyBytes verifies without padding, enforcing v.Floor only if floor is
+// set.
+func (v Verifier) verifyBytes(ctx context.Context, encoded string, pwd []byte, types Argon2Type, floor bool) error {
+	limits := v.Limits.WithDefaults()
+
+	switch types {
+	case Argon2D, Argon2I, Argon2Id:
+	default:
+		return newError(Argon2IncorrectType, "")
+	}
+
+	if int64(len(pwd)) > int64(limits.MaxPwdLen) {
+		return newError(Argon2PwdTooLong, "Pwd")
+	}
+
+	// A sealed string is its base64 plaintext and a short header, within
+	// twice the bound of the plaintext, which is checked once opened
+	maxLen := limits.maxEncodedLength()
+	if envelope.IsSealed(encoded) {
+		maxLen *= 2
+	}
+	encodedLen := uint64(len(encoded))
+	if encodedLen == 0 || encodedLen > maxLen {
+		return newError(Argon2DecodingFail, "")
+	}
+	encoded, err := v.open(encoded)
+	if err != nil {
+		return err
+	}
+	if uint64(len(encoded)) > limits.maxEncodedLength() {
+		return newError(Argon2DecodingFail, "")
+	}
+
+	actx, hash, err := DecodeString(Argon2Context{}, encoded, types)
+	if err != nil {
+		return err
+	}
+	if err = limits.Check(actx, len(pwd)); err != nil {
+		return err
+	}
+	if floor {
+		if err = v.Floor.Check(actx, types); err != nil && !v.now().Before(v.Floor.LegacyUntil) {
+			return err
+		}
+	}
+
+	if actx.KeyID != "" {
+		if v.Keys == nil {
+			return newError(Argon2UnknownKey, "keyid")
+		}
+		if actx.Secret, err = v.Keys.Key(actx.KeyID); err != nil {
+			return &Error{Code: Argon2UnknownKey, Field: "keyid", Err: err}
+		}
+		defer wipe(actx.Secret)
+	}
+
+	key, err := argon2Key(ctx, actx, pwd, types)
+	if err != nil {
+		return err
+	}
+	defer wipe(key)
+
+	if subtle.ConstantTimeCompare([]byte(hash), key) == 1 {
+		return nil
+	}
+
+	return newError(Argon2VerifyMismatch, "")
+}
@@ -0,0 +1,114 @@
+package agron2_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+)
+
+func TestVerifierLimits(t *testing.T) {
+	salt := "c29tZXNhbHQ"
+	hash := "wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+	for i, v := range []struct {
+		encoded string
+		pwd     string
+		want    error
+	}{
+		{"$argon2i$v=19$m=4294967295,t=2,p=1$" + salt + "$" + hash, "password", agron2.ErrMemoryTooMuch},
+		{"$argon2i$v=19$m=65536,t=4294967295,p=1$" + salt + "$" + hash, "password", agron2.ErrTimeTooLarge},
+		{"$argon2i$v=19$m=65536,t=2,p=255$" + salt + "$" + hash, "password", agron2.ErrThreadsTooMany},
+		{"$argon2i$v=19$m=65536,t=2,p=1$" + strings.Repeat("c29tZXNhbHQ", 20) + "$" + hash, "password", agron2.ErrSaltTooLong},
+		{"$argon2i$v=19$m=65536,t=2,p=1$" + salt + "$" + strings.Repeat(hash, 4), "password", agron2.ErrSecretTooLong},
+		{"$argon2i$v=19$m=65536,t=2,p=1$" + salt + "$" + hash, strings.Repeat("p", 4097), agron2.ErrPwdTooLong},
+		{"$argon2i$v=19$m=65536,t=2,p=1$" + strings.Repeat("c29tZXNhbHQ", 200) + "$" + hash, "password", agron2.ErrDecoding},
+	} {
+		if err := agron2.Argon2Verify(v.encoded, v.pwd, agron2.Argon2I); !errors.Is(err, v.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, v.want)
+		}
+	}
+}
+
+func TestVerifierCustomLimits(t *testing.T) {
+	encoded := "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+
+	v := agron2.Verifier{Limits: agron2.DecodeLimits{MaxMemory: 1024}}
+	if err := v.Verify(context.Background(), encoded, "password", agron2.Argon2I); !errors.Is(err, agron2.ErrMemoryTooMuch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMemoryTooMuch)
+	}
+
+	var e *agron2.Error
+	if err := v.VerifyAny(context.Background(), encoded, "password"); !errors.As(err, &e) || e.Field != "m" {
+		t.Errorf("got: %v, want an error on m", err)
+	}
+
+	v.Limits.MaxMemory = 65536
+	if err := v.Verify(context.Background(), encoded, "password", agron2.Argon2I); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+}
+
+func TestVerifierFloor(t *testing.T) {
+	weak, err := agron2.Argon2Hash("password", "somesalt", 1, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	strong, err := agron2.Argon2Hash("password", "somesalt12345678", 2, 256, 1, 32, agron2.Argon2Version13, agron2.Argon2Id)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	floor := agron2.StrengthFloor{
+		Types:      []agron2.Argon2Type{agron2.Argon2Id},
+		MinMemory:  256,
+		MinTime:    2,
+		MinSaltLen: 16,
+	}
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	v := agron2.Verifier{Floor: floor, Now: func() time.Time { return now }}
+
+	var e *agron2.Error
+	err = v.VerifyAny(context.Background(), weak, "password")
+	if !errors.Is(err, agron2.ErrTooWeak) || !errors.As(err, &e) || e.Field != "id" {
+		t.Errorf("weak - got: %v, want: %v on id", err, agron2.ErrTooWeak)
+	}
+	if err := v.VerifyAny(context.Background(), weak, "wrong"); !errors.Is(err, agron2.ErrTooWeak) {
+		t.Errorf("weak with a wrong password - got: %v, want: %v", err, agron2.ErrTooWeak)
+	}
+	if err := v.VerifyAny(context.Background(), strong, "password"); err != nil {
+		t.Errorf("strong - got: %v, want: nil", err)
+	}
+
+	for i, f := range []struct {
+		field  string
+		modify func(*agron2.StrengthFloor)
+	}{
+		{"m", func(f *agron2.StrengthFloor) { f.MinMemory = 512 }},
+		{"t", func(f *agron2.StrengthFloor) { f.MinTime = 3 }},
+		{"salt", func(f *agron2.StrengthFloor) { f.MinSaltLen = 32 }},
+		{"hash", func(f *agron2.StrengthFloor) { f.MinHashLen = 64 }},
+	} {
+		v := v
+		f.modify(&v.Floor)
+		if err := v.VerifyAny(context.Background(), strong, "password"); !errors.As(err, &e) || e.Code != agron2.Argon2TooWeak || e.Field != f.field {
+			t.Errorf("Test %d - got: %v, want: %v on %s", i, err, agron2.ErrTooWeak, f.field)
+		}
+	}
+
+	// Legacy hashes are verified during the migration window
+	v.Floor.LegacyUntil = now.Add(time.Hour)
+	if err := v.VerifyAny(context.Background(), weak, "password"); err != nil {
+		t.Errorf("migration window - got: %v, want: nil", err)
+	}
+	if err := v.VerifyAny(context.Background(), weak, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("migration window - got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+
+	now = now.Add(time.Hour)
+	if err := v.VerifyAny(context.Background(), weak, "password"); !errors.Is(err, agron2.ErrTooWeak) {
+		t.Errorf("after the migration window - got: %v, want: %v", err, agron2.ErrTooWeak)
+	}
+}
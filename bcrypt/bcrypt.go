@@ -0,0 +1,271 @@
+// Package bcrypt hashes passwords with bcrypt in the $2a$, $2b$ and $2y$
+// formats. Passwords longer than the 72 bytes bcrypt can take are rejected,
+// or pre-hashed into the $bcrypt-sha256$ format of passlib
+//
+//	$bcrypt-sha256$v=2,t=2b,r=<cost>$<salt>$<hash>
+//
+// whose key is the base64 HMAC-SHA256 of the password keyed with the salt.
+// Hashes of its first version, $bcrypt-sha256$2b,<cost>$<salt>$<hash> keyed
+// with the base64 SHA-256 of the password, are verified too.
+package bcrypt
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/pepper"
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+const (
+	MinCost           = 4
+	MaxCost           = 31
+	DefaultCost       = 12
+	DefaultMaxCost    = 16 // Largest cost accepted when verifying unless configured
+	MaxPasswordLength = 72 // Longest password bcrypt takes in bytes
+	SaltLength        = 16
+)
+
+const (
+	Variant2a = "2a"
+	Variant2b = "2b"
+	Variant2y = "2y"
+	PrehashID = "bcrypt-sha256"
+)
+
+// Hasher hashes passwords with bcrypt. Its methods make it a crypt.Hasher.
+type Hasher struct {
+	Cost    int    // cost of new hashes, DefaultCost if zero
+	Variant string // prefix of new hashes, Variant2b if empty
+	MaxCost int    // largest cost accepted when verifying, DefaultMaxCost if zero, never below Cost
+
+	// Prehash makes Hash pre-hash passwords longer than MaxPasswordLength
+	// instead of rejecting them.
+	Prehash bool
+}
+
+type hash struct {
+	variant string
+	cost    int
+	salt    string // encoded salt
+	sum     string // encoded hash
+	prehash bool
+	legacy  bool // version 1 of the $bcrypt-sha256$ format
+}
+
+func (h Hasher) cost() int {
+	if h.Cost == 0 {
+		return DefaultCost
+	}
+
+	return h.Cost
+}
+
+func (h Hasher) variant() string {
+	if h.Variant == "" {
+		return Variant2b
+	}
+
+	return h.Variant
+}
+
+// maxCost returns the largest cost Verify accepts, which is at least that of
+// new hashes so that h verifies every hash it writes.
+func (h Hasher) maxCost() int {
+	ret := h.MaxCost
+	if ret == 0 {
+		ret = DefaultMaxCost
+	}
+	if ret < h.cost() {
+		return h.cost()
+	}
+
+	return ret
+}
+
+func checkCost(cost int) error {
+	if cost < MinCost {
+		return kdf.NewError(kdf.TimeTooSmall, "cost")
+	}
+	if cost > MaxCost {
+		return kdf.NewError(kdf.TimeTooLarge, "cost")
+	}
+
+	return nil
+}
+
+func isVariant(variant string) bool {
+	return variant == Variant2a || variant == Variant2b || variant == Variant2y
+}
+
+// parse reads a $2x$ or $bcrypt-sha256$ string.
+func parse(encoded string) (hash, error) {
+	var ret hash
+	var rest string
+
+	if strings.HasPrefix(encoded, "$"+PrehashID+"$") {
+		fields := strings.Split(encoded[len(PrehashID)+2:], "$")
+		if len(fields) != 3 {
+			return hash{}, kdf.DecodingError("", fmt.Errorf("%w: fields", phc.ErrInvalid))
+		}
+
+		params := strings.Split(fields[0], ",")
+		if len(params) == 2 && isVariant(params[0]) {
+			// Version 1, <variant>,<cost>
+			params = []string{"v=1", "t=" + params[0], "r=" + params[1]}
+			ret.legacy = true
+		}
+		if len(params) != 3 || (params[0] != "v=2" && !ret.legacy) || !strings.HasPrefix(params[1], "t=") || !strings.HasPrefix(params[2], "r=") {
+			return hash{}, kdf.DecodingError("", fmt.Errorf("%w: parameters %q", phc.ErrInvalid, fields[0]))
+		}
+
+		cost, err := phc.ParseUint(params[2][2:], 8)
+		if err != nil {
+			return hash{}, kdf.DecodingError("r", err)
+		}
+
+		ret.variant, ret.cost, ret.prehash = params[1][2:], int(cost), true
+		rest = fields[1] + fields[2]
+		if len(fields[1]) != encodedSaltSize {
+			return hash{}, kdf.DecodingError("salt", nil)
+		}
+	} else {
+		// $2b$12$<salt><hash>
+		if len(encoded) != 7+encodedSaltSize+encodedHashSize || encoded[0] != '$' || encoded[3] != '$' || encoded[6] != '$' {
+			return hash{}, kdf.DecodingError("", fmt.Errorf("%w: not a bcrypt hash", phc.ErrInvalid))
+		}
+
+		cost, err := strconv.Atoi(encoded[4:6])
+		if err != nil || encoded[4] < '0' || encoded[4] > '9' {
+			return hash{}, kdf.DecodingError("cost", fmt.Errorf("%w: cost %q", phc.ErrInvalid, encoded[4:6]))
+		}
+
+		ret.variant, ret.cost = encoded[1:3], cost
+		rest = encoded[7:]
+	}
+
+	if !isVariant(ret.variant) {
+		return hash{}, kdf.NewError(kdf.IncorrectType, "id")
+	}
+	if err := checkCost(ret.cost); err != nil {
+		return hash{}, err
+	}
+	if len(rest) != encodedSaltSize+encodedHashSize {
+		return hash{}, kdf.DecodingError("hash", nil)
+	}
+
+	ret.salt, ret.sum = rest[:encodedSaltSize], rest[encodedSaltSize:]
+	if _, err := bcEncoding.DecodeString(ret.salt); err != nil {
+		return hash{}, kdf.DecodingError("salt", err)
+	}
+	if _, err := bcEncoding.DecodeString(ret.sum); err != nil {
+		return hash{}, kdf.DecodingError("hash", err)
+	}
+
+	return ret, nil
+}
+
+func (h hash) String() string {
+	if h.legacy {
+		return fmt.Sprintf("$%s$%s,%d$%s$%s", PrehashID, h.variant, h.cost, h.salt, h.sum)
+	}
+	if h.prehash {
+		return fmt.Sprintf("$%s$v=2,t=%s,r=%d$%s$%s", PrehashID, h.variant, h.cost, h.salt, h.sum)
+	}
+
+	return fmt.Sprintf("$%s$%02d$%s%s", h.variant, h.cost, h.salt, h.sum)
+}
+
+// ID returns the variant of new hashes.
+func (h Hasher) ID() string {
+	return h.variant()
+}
+
+// Hash hashes pwd with a random salt.
+func (h Hasher) Hash(pwd string) (string, error) {
+	ret := hash{variant: h.variant(), cost: h.cost()}
+	if !isVariant(ret.variant) {
+		return "", kdf.NewError(kdf.IncorrectType, "Variant")
+	}
+	if err := checkCost(ret.cost); err != nil {
+		return "", err
+	}
+
+	if len(pwd) > MaxPasswordLength {
+		if !h.Prehash {
+			return "", kdf.NewError(kdf.PwdTooLong, "Pwd")
+		}
+		ret.prehash = true
+	}
+
+	salt, err := kdf.GenerateSalt(nil, SaltLength)
+	if err != nil {
+		return "", err
+	}
+	ret.salt = bcEncoding.EncodeToString([]byte(salt))
+
+	key := ret.key(pwd)
+	defer wipe(key)
+	ret.sum = bcEncoding.EncodeToString(bcrypt(key, ret.cost, []byte(salt)))
+
+	return ret.String(), nil
+}
+
+// key returns the bcrypt key of pwd for h.
+func (h hash) key(pwd string) []byte {
+	if h.legacy {
+		sum := sha256.Sum256([]byte(pwd))
+		return []byte(base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	if h.prehash {
+		return pepper.Prehash([]byte(h.salt), []byte(pwd))
+	}
+
+	return []byte(pwd)
+}
+
+// Verify returns nil if pwd matches encoded, a $2a$, $2b$, $2y$ or
+// $bcrypt-sha256$ string. Passwords longer than MaxPasswordLength only match
+// pre-hashed strings.
+func (h Hasher) Verify(encoded, pwd string) error {
+	parsed, err := parse(encoded)
+	if err != nil {
+		return err
+	}
+	if parsed.cost > h.maxCost() {
+		return kdf.NewError(kdf.TimeTooLarge, "cost")
+	}
+	if !parsed.prehash && len(pwd) > MaxPasswordLength {
+		return kdf.NewError(kdf.PwdTooLong, "Pwd")
+	}
+
+	salt, _ := bcEncoding.DecodeString(parsed.salt)
+	want, _ := bcEncoding.DecodeString(parsed.sum)
+	key := parsed.key(pwd)
+	defer wipe(key)
+
+	sum := bcrypt(key, parsed.cost, salt)
+	defer wipe(sum)
+	if subtle.ConstantTimeCompare(sum, want) == 1 {
+		return nil
+	}
+
+	return kdf.NewError(kdf.VerifyMismatch, "")
+}
+
+// NeedsRehash reports whether encoded has a lower cost or another variant
+// than new hashes, or is of version 1 of the $bcrypt-sha256$ format. Costlier
+// hashes are kept, so that upgrading never weakens them.
+func (h Hasher) NeedsRehash(encoded string) (bool, error) {
+	parsed, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	return parsed.cost < h.cost() || parsed.variant != h.variant() || parsed.legacy, nil
+}
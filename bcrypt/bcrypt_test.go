@@ -0,0 +1,235 @@
+package bcrypt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/bcrypt"
+	xbcrypt "golang.org/x/crypto/bcrypt"
+)
+
+// OpenBSD test vectors
+var testVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy"},
+	{"U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"},
+	{"U*U*", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.VGOzA784oUp/Z0DY336zx7pLYAy0lwK"},
+	{"U*U*U", "$2a$05$XXXXXXXXXXXXXXXXXXXXXOAcXxm9kjPGEMsLznoKqmqw7tc8WCx4a"},
+	{"0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", "$2a$05$abcdefghijklmnopqrstuu5s2v8.iXieOjg/.AySBTTZIIVFJeBui"},
+}
+
+func TestVectors(t *testing.T) {
+	h := bcrypt.Hasher{}
+	for i, v := range testVectors {
+		// The variants only differ in their prefix for such passwords
+		for _, variant := range []string{"2a", "2b", "2y"} {
+			encoded := "$" + variant + v.encoded[3:]
+			if err := h.Verify(encoded, v.password); err != nil {
+				t.Errorf("Test %d %s - error: %v", i, variant, err)
+			}
+			if err := h.Verify(encoded, v.password+"x"); !errors.Is(err, agron2.ErrMismatch) && len(v.password) < bcrypt.MaxPasswordLength {
+				t.Errorf("Test %d %s - got: %v, want: %v", i, variant, err, agron2.ErrMismatch)
+			}
+		}
+	}
+}
+
+// Hashes of the passlib test suite for bcrypt_sha256, the last three of
+// passwords over 72 bytes sharing their first 72
+var passlibVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"password", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS"},
+	{strings.Repeat("abc123", 12), "$bcrypt-sha256$v=2,t=2b,r=5$X1g1nh3g0v4h6970O68cxe$zu1cloESVFIOsUIo7fCEgkdHaI9SSue"},
+	{strings.Repeat("abc123", 12) + "qwr", "$bcrypt-sha256$v=2,t=2b,r=5$X1g1nh3g0v4h6970O68cxe$CBF9csfEdW68xv3DwE6xSULXMtqEFP."},
+	{strings.Repeat("abc123", 12) + "xyz", "$bcrypt-sha256$v=2,t=2b,r=5$X1g1nh3g0v4h6970O68cxe$zC/1UDUG2ofEXB6Onr2vvyFzfhEOS3S"},
+}
+
+// Hash of the passlib test suite for the first version of bcrypt_sha256
+var passlibV1Vector = "$bcrypt-sha256$2b,5$5Hg1DKFqPE8C2aflZ5vVoe$12BjNE0p7axMg55.Y/mHsYiVuFBDQyu"
+
+func TestPasslib(t *testing.T) {
+	h := bcrypt.Hasher{}
+	for i, v := range passlibVectors {
+		if err := h.Verify(v.encoded, v.password); err != nil {
+			t.Errorf("Test %d - got: %v, want: nil", i, err)
+		}
+		if err := h.Verify(v.encoded, v.password+"x"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+	}
+
+	// Hashes of version 2 are those Hash writes
+	h = bcrypt.Hasher{Cost: 5, Prehash: true}
+	if needsRehash, err := h.NeedsRehash(passlibVectors[0].encoded); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	h = bcrypt.Hasher{}
+	for _, encoded := range []string{passlibV1Vector, strings.Replace(passlibV1Vector, "2b,", "2a,", 1)} {
+		if err := h.Verify(encoded, "password"); err != nil {
+			t.Errorf("%s - got: %v, want: nil", encoded, err)
+		}
+		if err := h.Verify(encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("%s - got: %v, want: %v", encoded, err, agron2.ErrMismatch)
+		}
+	}
+
+	// Version 1 hashes are upgraded to version 2
+	h = bcrypt.Hasher{Cost: 5, Prehash: true}
+	if needsRehash, err := h.NeedsRehash(passlibV1Vector); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true, nil", needsRehash, err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	for _, variant := range []string{"", "2a", "2b", "2y"} {
+		h := bcrypt.Hasher{Cost: bcrypt.MinCost, Variant: variant}
+		encoded, err := h.Hash("password")
+		if err != nil {
+			t.Fatalf("%s: failed to hash: %v", variant, err)
+		}
+
+		want := variant
+		if want == "" {
+			want = "2b"
+		}
+		if !strings.HasPrefix(encoded, "$"+want+"$04$") || len(encoded) != 60 || h.ID() != want {
+			t.Errorf("%s: got: %s", variant, encoded)
+		}
+
+		if err := h.Verify(encoded, "password"); err != nil {
+			t.Errorf("%s: got: %v, want: nil", variant, err)
+		}
+		if err := xbcrypt.CompareHashAndPassword([]byte(encoded), []byte("password")); err != nil {
+			t.Errorf("%s: x/crypto/bcrypt: %v", variant, err)
+		}
+	}
+
+	if _, err := (bcrypt.Hasher{Cost: 3}).Hash("password"); !errors.Is(err, agron2.ErrTimeTooSmall) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrTimeTooSmall)
+	}
+	if _, err := (bcrypt.Hasher{Variant: "2x"}).Hash("password"); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+}
+
+func TestVerifyGenerated(t *testing.T) {
+	encoded, err := xbcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	h := bcrypt.Hasher{Cost: bcrypt.MinCost}
+	if err := h.Verify(string(encoded), "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	// x/crypto/bcrypt makes $2a$ hashes
+	needsRehash, err := h.NeedsRehash(string(encoded))
+	if err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true", needsRehash, err)
+	}
+	h.Variant = bcrypt.Variant2a
+	if needsRehash, err = h.NeedsRehash(string(encoded)); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false", needsRehash, err)
+	}
+	h.Cost = 5
+	if needsRehash, err = h.NeedsRehash(string(encoded)); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true", needsRehash, err)
+	}
+
+	// A costlier hash than the policy is kept
+	costly, err := xbcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	h.Cost = bcrypt.MinCost
+	if needsRehash, err = h.NeedsRehash(string(costly)); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false", needsRehash, err)
+	}
+}
+
+func TestLongPassword(t *testing.T) {
+	long := strings.Repeat("p", bcrypt.MaxPasswordLength+1)
+
+	h := bcrypt.Hasher{Cost: bcrypt.MinCost}
+	if _, err := h.Hash(long); !errors.Is(err, agron2.ErrPwdTooLong) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrPwdTooLong)
+	}
+
+	h.Prehash = true
+	encoded, err := h.Hash(long)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$bcrypt-sha256$v=2,t=2b,r=4$") {
+		t.Errorf("got: %s", encoded)
+	}
+	if err := h.Verify(encoded, long); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	// The bytes past the limit count
+	if err := h.Verify(encoded, long[:bcrypt.MaxPasswordLength]+"q"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if needsRehash, err := h.NeedsRehash(encoded); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false", needsRehash, err)
+	}
+
+	// Short passwords keep the plain format
+	if encoded, err = h.Hash("password"); err != nil || !strings.HasPrefix(encoded, "$2b$04$") {
+		t.Errorf("got: %s, %v", encoded, err)
+	}
+
+	// Too long for a plain hash, it could only match once truncated
+	if err := h.Verify(testVectors[4].encoded, testVectors[4].password+"x"); !errors.Is(err, agron2.ErrPwdTooLong) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrPwdTooLong)
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	h := bcrypt.Hasher{}
+	for i, v := range []struct {
+		encoded string
+		want    error
+	}{
+		{"", agron2.ErrDecoding},
+		{"$2b$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWN", agron2.ErrDecoding},
+		{"$2b$5$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNyy", agron2.ErrDecoding},
+		{"$2b$-5$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrDecoding},
+		{"$2b$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9Cdcdxi*TWNy", agron2.ErrDecoding},
+		{"$2x$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrIncorrectType},
+		{"$2b$03$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrTimeTooSmall},
+		{"$2b$31$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrTimeTooLarge},
+		{"$bcrypt-sha256$v=2,t=2b,r=05$CCCCCCCCCCCCCCCCCCCCC.$7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrDecoding},
+		{"$bcrypt-sha256$v=1,t=2b,r=5$CCCCCCCCCCCCCCCCCCCCC.$7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", agron2.ErrDecoding},
+		{"$bcrypt-sha256$v=2,t=2b,r=5$CCCCCCCCCCCCCCCCCCCCC$7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNyC", agron2.ErrDecoding},
+	} {
+		if err := h.Verify(v.encoded, "password"); !errors.Is(err, v.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, v.want)
+		}
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	// A hasher verifies the hashes it writes whatever its MaxCost
+	h := bcrypt.Hasher{Cost: bcrypt.MinCost + 1, MaxCost: bcrypt.MinCost}
+	encoded, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if err := h.Verify(encoded, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	costly := "$2b$17$" + strings.TrimPrefix(encoded, "$2b$05$")
+	if err := (bcrypt.Hasher{Cost: 16}).Verify(costly, "password"); !errors.Is(err, agron2.ErrTimeTooLarge) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrTimeTooLarge)
+	}
+}
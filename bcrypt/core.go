@@ -0,0 +1,73 @@
+// https://cs.opensource.google/go/x/crypto/+/aa10faf2:bcrypt/bcrypt.go
+
+package bcrypt
+
+import (
+	"encoding/base64"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+const alphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var bcEncoding = base64.NewEncoding(alphabet).WithPadding(base64.NoPadding)
+
+const (
+	encodedSaltSize = 22
+	encodedHashSize = 31
+	maxCryptedHash  = 23
+)
+
+// magicCipherData is an IV for the 64 Blowfish encryption calls in
+// bcrypt(). It's the string "OrpheanBeholderScryDoubt" in big-endian bytes.
+var magicCipherData = []byte{
+	0x4f, 0x72, 0x70, 0x68,
+	0x65, 0x61, 0x6e, 0x42,
+	0x65, 0x68, 0x6f, 0x6c,
+	0x64, 0x65, 0x72, 0x53,
+	0x63, 0x72, 0x79, 0x44,
+	0x6f, 0x75, 0x62, 0x74,
+}
+
+func bcrypt(password []byte, cost int, salt []byte) []byte {
+	cipherData := make([]byte, len(magicCipherData))
+	copy(cipherData, magicCipherData)
+
+	c := expensiveBlowfishSetup(password, uint32(cost), salt)
+
+	for i := 0; i < 24; i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(cipherData[i:i+8], cipherData[i:i+8])
+		}
+	}
+
+	// Bug compatibility with C bcrypt implementations. We only encode 23 of
+	// the 24 bytes encrypted.
+	return cipherData[:maxCryptedHash]
+}
+
+func expensiveBlowfishSetup(key []byte, cost uint32, csalt []byte) *blowfish.Cipher {
+	// Bug compatibility with C bcrypt implementations. They use the trailing
+	// NULL in the key string during expansion.
+	ckey := make([]byte, len(key)+1)
+	copy(ckey, key)
+	defer wipe(ckey)
+
+	// The salt is never empty, so the key length is not checked
+	c, _ := blowfish.NewSaltedCipher(ckey, csalt)
+
+	var i, rounds uint64
+	rounds = 1 << cost
+	for i = 0; i < rounds; i++ {
+		blowfish.ExpandKey(ckey, c)
+		blowfish.ExpandKey(csalt, c)
+	}
+
+	return c
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,240 @@
+// Package crypt is a common interface to the password hashing algorithms of
+// this module. A Registry picks the algorithm of an encoded hash from its id,
+// so that hashes of several algorithms can be verified side by side while
+// they are migrated to a preferred one.
+package crypt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fikryfahrezy/crypt/pbkdf2"
+)
+
+var (
+	ErrUnknownID   = errors.New("crypt: unknown hash id")
+	ErrDuplicateID = errors.New("crypt: hash id already registered")
+	ErrNoPreferred = errors.New("crypt: no preferred hasher")
+)
+
+// Hasher is a password hashing algorithm with its parameters.
+type Hasher interface {
+	// ID returns the id of the hashes made by Hash, as found by HashID.
+	ID() string
+
+	// Hash returns the encoded hash of pwd with a new random salt.
+	Hash(pwd string) (string, error)
+
+	// Verify returns nil if pwd matches encoded.
+	Verify(encoded, pwd string) error
+
+	// NeedsRehash reports whether encoded was made with other parameters
+	// than Hash uses.
+	NeedsRehash(encoded string) (bool, error)
+}
+
+// Aliaser is implemented by Hashers whose hashes may have other ids than
+// ID, such as an agron2.Hasher sealing them with an envelope.Sealer. Register
+// adds them to the aliases it is given.
+type Aliaser interface {
+	Aliases() []string
+}
+
+// HashID returns the id of encoded: the first field of a $id$... string such
+// as a PHC string, or the text before the first $ of an id$... string.
+func HashID(encoded string) string {
+	s := strings.TrimPrefix(encoded, "$")
+	if i := strings.IndexByte(s, '$'); i >= 0 {
+		return s[:i]
+	}
+
+	return ""
+}
+
+// Registry dispatches encoded hashes to the Hasher registered for their id
+// and hashes new passwords with its preferred Hasher.
+type Registry struct {
+	mu        sync.RWMutex
+	hashers   map[string]Hasher
+	preferred Hasher
+}
+
+// NewRegistry returns a Registry preferring preferred, which is registered
+// with the others under their ids.
+func NewRegistry(preferred Hasher, others ...Hasher) (*Registry, error) {
+	r := &Registry{}
+	for _, h := range append([]Hasher{preferred}, others...) {
+		if err := r.Register(h); err != nil {
+			return nil, err
+		}
+	}
+	r.preferred = preferred
+
+	return r, nil
+}
+
+// Register makes r verify hashes whose id is h.ID(), one of aliases or, for an
+// Aliaser, one of its Aliases with h.
+func (r *Registry) Register(h Hasher, aliases ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := append([]string{h.ID()}, aliases...)
+	if a, ok := h.(Aliaser); ok {
+		ids = append(ids, a.Aliases()...)
+	}
+	for _, id := range ids {
+		if _, ok := r.hashers[id]; ok {
+			return fmt.Errorf("%w: %q", ErrDuplicateID, id)
+		}
+	}
+
+	if r.hashers == nil {
+		r.hashers = make(map[string]Hasher)
+	}
+	for _, id := range ids {
+		r.hashers[id] = h
+	}
+
+	return nil
+}
+
+// SetPreferred makes the Hasher registered for id the one new hashes are made
+// with.
+func (r *Registry) SetPreferred(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.hashers[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownID, id)
+	}
+	r.preferred = h
+
+	return nil
+}
+
+// Preferred returns the Hasher new hashes are made with.
+func (r *Registry) Preferred() (Hasher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.preferred == nil {
+		return nil, ErrNoPreferred
+	}
+
+	return r.preferred, nil
+}
+
+// Lookup returns the Hasher registered for the id of encoded.
+func (r *Registry) Lookup(encoded string) (Hasher, error) {
+	id := HashID(encoded)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h, ok := r.hashers[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownID, id)
+	}
+
+	return h, nil
+}
+
+// Hash hashes pwd with the preferred Hasher.
+func (r *Registry) Hash(pwd string) (string, error) {
+	h, err := r.Preferred()
+	if err != nil {
+		return "", err
+	}
+
+	return h.Hash(pwd)
+}
+
+// Verify verifies pwd with the Hasher registered for the id of encoded.
+func (r *Registry) Verify(encoded, pwd string) error {
+	h, err := r.Lookup(encoded)
+	if err != nil {
+		return err
+	}
+
+	return h.Verify(encoded, pwd)
+}
+
+// NeedsRehash reports whether encoded was made by another Hasher than the
+// preferred one, or by it with other parameters.
+func (r *Registry) NeedsRehash(encoded string) (bool, error) {
+	preferred, err := r.Preferred()
+	if err != nil {
+		return false, err
+	}
+	h, err := r.Lookup(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if h.ID() != preferred.ID() {
+		return true, nil
+	}
+
+	return preferred.NeedsRehash(encoded)
+}
+
+// VerifyAndUpgrade verifies pwd against encoded. When it matches and
+// NeedsRehash is true, it returns a new hash made by the preferred Hasher,
+// otherwise it returns an empty string.
+func (r *Registry) VerifyAndUpgrade(encoded, pwd string) (string, error) {
+	if err := r.Verify(encoded, pwd); err != nil {
+		return "", err
+	}
+
+	needsRehash, err := r.NeedsRehash(encoded)
+	if err != nil || !needsRehash {
+		return "", err
+	}
+
+	return r.Hash(pwd)
+}
+
+// Default verifies every Argon2 type, bcrypt, scrypt, SHA-crypt and PBKDF2
+// and hashes with agron2.Argon2DefaultPolicy. Built with the fips tag, it
+// only verifies and hashes PBKDF2, of salts of pbkdf2.MinSaltLength bytes
+// or more.
+var Default = newDefault()
+
+// pbkdf2Aliases are the ids, besides pbkdf2.IDSHA256, of the PBKDF2 hashes
+// of every digest and format.
+var pbkdf2Aliases = []string{
+	pbkdf2.IDSHA1,
+	pbkdf2.IDSHA512,
+	pbkdf2.PasslibIDSHA1,
+	pbkdf2.DjangoIDSHA1,
+	pbkdf2.DjangoIDSHA256,
+}
+
+// Register registers h with Default.
+func Register(h Hasher, aliases ...string) error {
+	return Default.Register(h, aliases...)
+}
+
+// Hash hashes pwd with Default.
+func Hash(pwd string) (string, error) {
+	return Default.Hash(pwd)
+}
+
+// Verify verifies pwd against encoded with Default.
+func Verify(encoded, pwd string) error {
+	return Default.Verify(encoded, pwd)
+}
+
+// NeedsRehash runs NeedsRehash of Default.
+func NeedsRehash(encoded string) (bool, error) {
+	return Default.NeedsRehash(encoded)
+}
+
+// VerifyAndUpgrade runs VerifyAndUpgrade of Default.
+func VerifyAndUpgrade(encoded, pwd string) (string, error) {
+	return Default.VerifyAndUpgrade(encoded, pwd)
+}
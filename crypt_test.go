@@ -0,0 +1,148 @@
+package crypt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt"
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/envelope"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+// plain is a Hasher storing passwords as is, standing in for a third-party
+// algorithm.
+type plain struct{}
+
+func (plain) ID() string { return "plain" }
+
+func (plain) Hash(pwd string) (string, error) { return "$plain$" + pwd, nil }
+
+func (plain) Verify(encoded, pwd string) error {
+	if encoded != "$plain$"+pwd {
+		return agron2.ErrMismatch
+	}
+	return nil
+}
+
+func (plain) NeedsRehash(encoded string) (bool, error) { return false, nil }
+
+var testPolicy = agron2.Argon2Policy{Type: agron2.Argon2Id, Mcost: 64, Tcost: 2, Threads: 1}
+
+func TestHashID(t *testing.T) {
+	for _, v := range []struct {
+		encoded string
+		want    string
+	}{
+		{"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHQ$aGFzaA", "argon2id"},
+		{"$2b$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW", "2b"},
+		{"pbkdf2_sha256$260000$salt$hash", "pbkdf2_sha256"},
+		{"", ""},
+		{"plaintext", ""},
+	} {
+		if got := crypt.HashID(v.encoded); got != v.want {
+			t.Errorf("%q - got: %q, want: %q", v.encoded, got, v.want)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := crypt.NewRegistry(agron2.Hasher{Policy: testPolicy}, plain{})
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+	if err := r.Register(plain{}); !errors.Is(err, crypt.ErrDuplicateID) {
+		t.Errorf("got: %v, want: %v", err, crypt.ErrDuplicateID)
+	}
+
+	legacy, err := agron2.Argon2Hash("password", "somesalt", 1, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2I)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	// argon2i is not registered until aliased
+	if err := r.Verify(legacy, "password"); !errors.Is(err, crypt.ErrUnknownID) {
+		t.Errorf("got: %v, want: %v", err, crypt.ErrUnknownID)
+	}
+	if err := r.Register(agron2.Hasher{Policy: agron2.Argon2Policy{Type: agron2.Argon2I}}, "argon2d"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	for i, v := range []struct {
+		encoded     string
+		needsRehash bool
+	}{
+		{legacy, true},
+		{"$plain$password", true},
+	} {
+		if err := r.Verify(v.encoded, "password"); err != nil {
+			t.Errorf("Test %d - got: %v, want: nil", i, err)
+		}
+		if err := r.Verify(v.encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+
+		needsRehash, err := r.NeedsRehash(v.encoded)
+		if err != nil || needsRehash != v.needsRehash {
+			t.Errorf("Test %d - got: %v, %v, want: %v", i, needsRehash, err, v.needsRehash)
+		}
+
+		upgraded, err := r.VerifyAndUpgrade(v.encoded, "password")
+		if err != nil || !strings.HasPrefix(upgraded, "$argon2id$") {
+			t.Fatalf("Test %d - got: %q, %v, want an argon2id hash", i, upgraded, err)
+		}
+		if again, err := r.VerifyAndUpgrade(upgraded, "password"); err != nil || again != "" {
+			t.Errorf("Test %d - got: %q, %v, want no upgrade", i, again, err)
+		}
+	}
+
+	if err := r.SetPreferred("plain"); err != nil {
+		t.Fatalf("failed to set preferred: %v", err)
+	}
+	if encoded, err := r.Hash("password"); err != nil || encoded != "$plain$password" {
+		t.Errorf("got: %q, %v", encoded, err)
+	}
+	if err := r.SetPreferred("scrypt"); !errors.Is(err, crypt.ErrUnknownID) {
+		t.Errorf("got: %v, want: %v", err, crypt.ErrUnknownID)
+	}
+}
+
+func TestRegistryEnvelope(t *testing.T) {
+	policy := testPolicy
+	policy.Envelope = &envelope.Sealer{Keys: &pepper.Keyring{
+		Provider: pepper.Static{"e1": []byte("0123456789abcdef0123456789abcdef")},
+		Current:  "e1",
+	}}
+
+	r, err := crypt.NewRegistry(agron2.Hasher{Policy: policy}, plain{})
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	sealed, err := r.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !envelope.IsSealed(sealed) {
+		t.Fatalf("got an unsealed hash: %s", sealed)
+	}
+
+	if err := r.Verify(sealed, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := r.Verify(sealed, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrMismatch)
+	}
+	if needsRehash, err := r.NeedsRehash(sealed); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+	if upgraded, err := r.VerifyAndUpgrade(sealed, "password"); err != nil || upgraded != "" {
+		t.Errorf("got: %q, %v, want no upgrade", upgraded, err)
+	}
+
+	// Sealed hashes of two hashers cannot be told apart
+	if err := r.Register(agron2.Hasher{Policy: agron2.Argon2Policy{Type: agron2.Argon2I, Envelope: policy.Envelope}}); !errors.Is(err, crypt.ErrDuplicateID) {
+		t.Errorf("got: %v, want: %v", err, crypt.ErrDuplicateID)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !fips
+// +build !fips
+
+package crypt
+
+import (
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/bcrypt"
+	"github.com/fikryfahrezy/crypt/pbkdf2"
+	"github.com/fikryfahrezy/crypt/scrypt"
+	"github.com/fikryfahrezy/crypt/shacrypt"
+)
+
+func newDefault() *Registry {
+	r := &Registry{}
+	if err := r.Register(agron2.Hasher{Policy: agron2.Argon2DefaultPolicy}, "argon2i", "argon2d"); err != nil {
+		panic(err)
+	}
+	if err := r.Register(bcrypt.Hasher{Prehash: true}, bcrypt.Variant2a, bcrypt.Variant2y, bcrypt.PrehashID); err != nil {
+		panic(err)
+	}
+	if err := r.Register(scrypt.Hasher{}, "7"); err != nil {
+		panic(err)
+	}
+	if err := r.Register(shacrypt.Hasher{}, shacrypt.ID256); err != nil {
+		panic(err)
+	}
+	if err := r.Register(pbkdf2.Hasher{}, pbkdf2Aliases...); err != nil {
+		panic(err)
+	}
+	if err := r.SetPreferred("argon2id"); err != nil {
+		panic(err)
+	}
+
+	return r
+}
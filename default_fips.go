@@ -0,0 +1,18 @@
+//go:build fips
+// +build fips
+
+package crypt
+
+import "github.com/fikryfahrezy/crypt/pbkdf2"
+
+func newDefault() *Registry {
+	r := &Registry{}
+	if err := r.Register(pbkdf2.Hasher{}, pbkdf2Aliases...); err != nil {
+		panic(err)
+	}
+	if err := r.SetPreferred(pbkdf2.IDSHA256); err != nil {
+		panic(err)
+	}
+
+	return r
+}
@@ -0,0 +1,45 @@
+//go:build fips
+// +build fips
+
+package crypt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt"
+	"github.com/fikryfahrezy/crypt/kdf"
+)
+
+func TestDefault(t *testing.T) {
+	encoded, err := crypt.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$pbkdf2-sha256$") {
+		t.Errorf("got: %s, want a PBKDF2 hash", encoded)
+	}
+	if err := crypt.Verify(encoded, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+
+	if err := crypt.Verify("pbkdf2_sha256$1000$seasaltseasalt12$r6c5NE/kJrj1WWYsUfW7uB73rhma/YIF/aEQhbnv5Qk=", "lètmein"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if err := crypt.Verify("pbkdf2_sha256$1000$seasalt$JgZryXe2Ga8ysg6XbzkLpTdyPQrHqsinbL9BnnhgX4A=", "lètmein"); !errors.Is(err, kdf.ErrSaltTooShort) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrSaltTooShort)
+	}
+
+	// Nothing but PBKDF2 is available
+	for _, encoded := range []string{
+		"$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$aGFzaA",
+		"$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+		"$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D",
+		"$scrypt$ln=4,r=8,p=1$c29tZXNhbHQ$aGFzaA",
+	} {
+		if err := crypt.Verify(encoded, "password"); !errors.Is(err, crypt.ErrUnknownID) {
+			t.Errorf("%s - got: %v, want: %v", encoded, err, crypt.ErrUnknownID)
+		}
+	}
+}
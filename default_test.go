@@ -0,0 +1,82 @@
+//go:build !fips
+// +build !fips
+
+package crypt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt"
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/scrypt"
+)
+
+func TestDefault(t *testing.T) {
+	legacy, err := agron2.Argon2Hash("password", "somesalt", 1, 64, 1, 32, agron2.Argon2Version13, agron2.Argon2D)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	if err := crypt.Verify(legacy, "password"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if needsRehash, err := crypt.NeedsRehash(legacy); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true", needsRehash, err)
+	}
+	// bcrypt hashes verify and are upgraded to Argon2
+	for _, encoded := range []string{
+		"$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+		"$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+		"$2y$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+	} {
+		if err := crypt.Verify(encoded, "U*U"); err != nil {
+			t.Errorf("%s - got: %v, want: nil", encoded, err)
+		}
+		if needsRehash, err := crypt.NeedsRehash(encoded); err != nil || !needsRehash {
+			t.Errorf("%s - got: %v, %v, want: true", encoded, needsRehash, err)
+		}
+	}
+
+	// and so do scrypt hashes in either format
+	scryptHash, err := scrypt.Hasher{Params: scrypt.Params{LogN: 4}}.Hash("pleaseletmein")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	for _, encoded := range []string{scryptHash, "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D"} {
+		if err := crypt.Verify(encoded, "pleaseletmein"); err != nil {
+			t.Errorf("%s - got: %v, want: nil", encoded, err)
+		}
+		if needsRehash, err := crypt.NeedsRehash(encoded); err != nil || !needsRehash {
+			t.Errorf("%s - got: %v, %v, want: true", encoded, needsRehash, err)
+		}
+	}
+
+	// and so do PBKDF2 hashes of Django
+	django := "pbkdf2_sha256$1000$seasalt$JgZryXe2Ga8ysg6XbzkLpTdyPQrHqsinbL9BnnhgX4A="
+	if err := crypt.Verify(django, "lètmein"); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if needsRehash, err := crypt.NeedsRehash(django); err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true", needsRehash, err)
+	}
+
+	// SHA-crypt hashes of shadow files are upgraded to Argon2id
+	for _, encoded := range []string{
+		"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+		"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+	} {
+		if _, err := crypt.VerifyAndUpgrade(encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("%s - got: %v, want: %v", encoded, err, agron2.ErrMismatch)
+		}
+		upgraded, err := crypt.VerifyAndUpgrade(encoded, "Hello world!")
+		if err != nil || !strings.HasPrefix(upgraded, "$argon2id$") {
+			t.Errorf("%s - got: %q, %v, want Argon2id hash", encoded, upgraded, err)
+		}
+	}
+
+	if err := crypt.Verify("$plain$password", "password"); !errors.Is(err, crypt.ErrUnknownID) {
+		t.Errorf("got: %v, want: %v", err, crypt.ErrUnknownID)
+	}
+}
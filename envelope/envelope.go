@@ -0,0 +1,215 @@
+// Package envelope encrypts encoded password hashes at rest. A sealed hash is
+// a PHC string of its own
+//
+//	$aead$v=1$alg=<algorithm>,keyid=<key id>$<nonce>$<ciphertext>
+//
+// where the key id names a key of a pepper.Keyring, so that keys can be
+// rotated by resealing stored hashes without knowing the passwords.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/pepper"
+	"github.com/fikryfahrezy/crypt/phc"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	ID        = "aead" // PHC id of sealed hashes
+	Version   = 1
+	KeyLength = 32 // Key length in bytes of every algorithm
+)
+
+type Algorithm string
+
+const (
+	AES256GCM         Algorithm = "aes256gcm"
+	XChaCha20Poly1305 Algorithm = "xchacha20poly1305"
+)
+
+var (
+	ErrNotSealed = errors.New("envelope: not a sealed hash")
+	ErrDecrypt   = errors.New("envelope: message authentication failed")
+	ErrAlgorithm = errors.New("envelope: unknown algorithm")
+	ErrKeyLength = errors.New("envelope: key must be 32 bytes")
+)
+
+func newAEAD(alg Algorithm, key []byte) (cipher.AEAD, error) {
+	if len(key) != KeyLength {
+		return nil, ErrKeyLength
+	}
+
+	switch alg {
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrAlgorithm, alg)
+}
+
+// IsSealed reports whether s looks like a sealed hash.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, "$"+ID+"$")
+}
+
+// Sealer seals and opens encoded hashes with the keys of Keys, sealing with
+// the current one.
+type Sealer struct {
+	Keys      *pepper.Keyring
+	Algorithm Algorithm // algorithm of new envelopes, AES256GCM if empty
+	Rand      io.Reader // source of nonces, crypto/rand if nil
+}
+
+// header returns the unsealed part of an envelope, which is authenticated as
+// additional data.
+func header(alg Algorithm, keyID string) phc.Hash {
+	return phc.Hash{
+		ID:         ID,
+		Version:    Version,
+		HasVersion: true,
+		Params: []phc.Param{
+			{Name: "alg", Value: string(alg)},
+			{Name: "keyid", Value: phc.B64.EncodeToString([]byte(keyID))},
+		},
+	}
+}
+
+// Seal encrypts encoded with the current key.
+func (s Sealer) Seal(encoded string) (string, error) {
+	alg := s.Algorithm
+	if alg == "" {
+		alg = AES256GCM
+	}
+	r := s.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+
+	keyID, key, err := s.Keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(r, nonce); err != nil {
+		return "", err
+	}
+
+	h := header(alg, keyID)
+	sealed := aead.Seal(nil, nonce, []byte(encoded), []byte(h.String()))
+	h.Salt = phc.B64.EncodeToString(nonce)
+	h.Hash = phc.B64.EncodeToString(sealed)
+
+	return h.String(), nil
+}
+
+// parse returns the algorithm, key id, nonce and ciphertext of sealed.
+func parse(sealed string) (phc.Hash, Algorithm, string, error) {
+	if !IsSealed(sealed) {
+		return phc.Hash{}, "", "", ErrNotSealed
+	}
+
+	h, err := phc.Parse(sealed)
+	if err != nil {
+		return phc.Hash{}, "", "", err
+	}
+	if !h.HasVersion || h.Version != Version || len(h.Params) != 2 || h.Salt == "" || h.Hash == "" {
+		return phc.Hash{}, "", "", fmt.Errorf("%w: %s", phc.ErrInvalid, "envelope")
+	}
+
+	alg, _ := h.Param("alg")
+	b64KeyID, _ := h.Param("keyid")
+	keyID, err := phc.B64.DecodeString(b64KeyID)
+	if err != nil {
+		return phc.Hash{}, "", "", fmt.Errorf("%w: keyid %q", phc.ErrInvalid, b64KeyID)
+	}
+
+	return h, Algorithm(alg), string(keyID), nil
+}
+
+// Open decrypts sealed with its key, current or retired.
+func (s Sealer) Open(sealed string) (string, error) {
+	h, alg, keyID, err := parse(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := s.Keys.Key(keyID)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := phc.B64.DecodeString(h.Salt)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("%w: nonce %q", phc.ErrInvalid, h.Salt)
+	}
+	ciphertext, err := phc.B64.DecodeString(h.Hash)
+	if err != nil {
+		return "", fmt.Errorf("%w: ciphertext", phc.ErrInvalid)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(header(alg, keyID).String()))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsReseal reports whether s is not sealed, or sealed with another key or
+// algorithm than Seal would use.
+func (s Sealer) NeedsReseal(encoded string) (bool, error) {
+	if !IsSealed(encoded) {
+		return true, nil
+	}
+
+	_, alg, keyID, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	want := s.Algorithm
+	if want == "" {
+		want = AES256GCM
+	}
+
+	return alg != want || s.Keys.IsRetired(keyID), nil
+}
+
+// Reseal seals an unsealed hash, or opens and seals again a hash for which
+// NeedsReseal is true. Otherwise it returns encoded as is.
+func (s Sealer) Reseal(encoded string) (string, error) {
+	needsReseal, err := s.NeedsReseal(encoded)
+	if err != nil || !needsReseal {
+		return encoded, err
+	}
+
+	if IsSealed(encoded) {
+		if encoded, err = s.Open(encoded); err != nil {
+			return "", err
+		}
+	}
+
+	return s.Seal(encoded)
+}
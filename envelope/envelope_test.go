@@ -0,0 +1,110 @@
+package envelope_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/envelope"
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+const encoded = "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+
+var testKeys = pepper.Static{
+	"e1": []byte("0123456789abcdef0123456789abcdef"),
+	"e2": []byte("fedcba9876543210fedcba9876543210"),
+}
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range []envelope.Algorithm{"", envelope.AES256GCM, envelope.XChaCha20Poly1305} {
+		s := envelope.Sealer{Keys: &pepper.Keyring{Provider: testKeys, Current: "e1"}, Algorithm: alg}
+
+		sealed, err := s.Seal(encoded)
+		if err != nil {
+			t.Fatalf("%s: failed to seal: %v", alg, err)
+		}
+		if !envelope.IsSealed(sealed) || strings.Contains(sealed, "c29tZXNhbHQ") {
+			t.Errorf("%s: got: %s", alg, sealed)
+		}
+
+		again, err := s.Seal(encoded)
+		if err != nil || again == sealed {
+			t.Errorf("%s: sealing twice gave the same nonce: %s", alg, again)
+		}
+
+		opened, err := s.Open(sealed)
+		if err != nil || opened != encoded {
+			t.Errorf("%s: got: %s, %v, want: %s", alg, opened, err, encoded)
+		}
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	s := envelope.Sealer{Keys: &pepper.Keyring{Provider: testKeys, Current: "e1", Retired: []string{"e2"}}}
+	sealed, err := s.Seal(encoded)
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	// Swapping the algorithm or the key id breaks the authentication
+	for i, tampered := range []string{
+		strings.Replace(sealed, "keyid=ZTE", "keyid=ZTI", 1),
+		sealed[:len(sealed)-2] + "AA",
+	} {
+		if _, err := s.Open(tampered); !errors.Is(err, envelope.ErrDecrypt) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, envelope.ErrDecrypt)
+		}
+	}
+
+	if _, err := s.Open(strings.Replace(sealed, "alg=aes256gcm", "alg=rot13", 1)); !errors.Is(err, envelope.ErrAlgorithm) {
+		t.Errorf("got: %v, want: %v", err, envelope.ErrAlgorithm)
+	}
+	if _, err := s.Open(encoded); !errors.Is(err, envelope.ErrNotSealed) {
+		t.Errorf("got: %v, want: %v", err, envelope.ErrNotSealed)
+	}
+
+	short := envelope.Sealer{Keys: &pepper.Keyring{Provider: pepper.Static{"k": []byte("0123456789abcdef")}, Current: "k"}}
+	if _, err := short.Seal(encoded); !errors.Is(err, envelope.ErrKeyLength) {
+		t.Errorf("got: %v, want: %v", err, envelope.ErrKeyLength)
+	}
+}
+
+func TestReseal(t *testing.T) {
+	old := envelope.Sealer{Keys: &pepper.Keyring{Provider: testKeys, Current: "e1"}}
+	sealed, err := old.Seal(encoded)
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	s := envelope.Sealer{Keys: &pepper.Keyring{Provider: testKeys, Current: "e2", Retired: []string{"e1"}}}
+	for i, v := range []struct {
+		encoded string
+		want    bool
+	}{
+		{encoded, true},
+		{sealed, true},
+	} {
+		needsReseal, err := s.NeedsReseal(v.encoded)
+		if err != nil || needsReseal != v.want {
+			t.Errorf("Test %d - got: %v, %v, want: %v", i, needsReseal, err, v.want)
+		}
+
+		resealed, err := s.Reseal(v.encoded)
+		if err != nil || !strings.Contains(resealed, "keyid=ZTI$") {
+			t.Fatalf("Test %d - got: %s, %v", i, resealed, err)
+		}
+		if opened, err := s.Open(resealed); err != nil || opened != encoded {
+			t.Errorf("Test %d - got: %s, %v, want: %s", i, opened, err, encoded)
+		}
+
+		if again, err := s.Reseal(resealed); err != nil || again != resealed {
+			t.Errorf("Test %d - resealed again: %s, %v", i, again, err)
+		}
+	}
+
+	s.Algorithm = envelope.XChaCha20Poly1305
+	if needsReseal, err := s.NeedsReseal(sealed); err != nil || !needsReseal {
+		t.Errorf("got: %v, %v, want: true", needsReseal, err)
+	}
+}
@@ -0,0 +1,255 @@
+// Package kdf holds what the password hashing packages of crypt share: their
+// error codes and Error type, and the generation of random salts. It depends
+// on no hashing algorithm, so that FIPS builds link none but their own.
+package kdf
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error codes, those of the Argon2 reference implementation followed by ours.
+const (
+	Ok = iota
+	PwdTooShort
+	PwdTooLong
+	SaltTooShort
+	SaltTooLong
+	SecretTooShort
+	SecretTooLong
+	TimeTooSmall
+	TimeTooLarge
+	MemoryTooLittle
+	MemoryTooMuch
+	PwdPtrMismatch
+	SaltPtrMismatch
+	SecretPtrMismatch
+	IncorrectType
+	ThreadsTooFew
+	ThreadsTooMany
+	DecodingFail
+	VerifyMismatch
+	AdTooShort
+	AdTooLong
+	IncorrectVersion
+	SaltLowEntropy
+	TooWeak
+	UnknownKey
+)
+
+// ErrorMessage returns the message of an error code.
+func ErrorMessage(errorCode int) string {
+	switch errorCode {
+	case Ok:
+		return "OK"
+	case PwdTooShort:
+		return "Password is too short"
+	case PwdTooLong:
+		return "Password is too long"
+	case SaltTooShort:
+		return "Salt is too short"
+	case SaltTooLong:
+		return "Salt is too long"
+	case SecretTooShort:
+		return "Secret is too short"
+	case SecretTooLong:
+		return "Secret is too long"
+	case TimeTooSmall:
+		return "Time cost is too small"
+	case TimeTooLarge:
+		return "Time cost is too large"
+	case MemoryTooLittle:
+		return "Memory cost is too small"
+	case MemoryTooMuch:
+		return "Memory cost is too large"
+	case PwdPtrMismatch:
+		return "Password pointer is NULL, but password length is not 0"
+	case SaltPtrMismatch:
+		return "Salt pointer is NULL, but salt length is not 0"
+	case SecretPtrMismatch:
+		return "Secret pointer is NULL, but secret length is not 0"
+	case IncorrectType:
+		return "There is no such version of Argon2"
+	case ThreadsTooFew:
+		return "Not enough threads"
+	case ThreadsTooMany:
+		return "Too many threads"
+	case DecodingFail:
+		return "Decoding failed"
+	case VerifyMismatch:
+		return "The password does not match the supplied hash"
+	case AdTooShort:
+		return "Associated data is too short"
+	case AdTooLong:
+		return "Associated data is too long"
+	case IncorrectVersion:
+		return "There is no such version number of Argon2"
+	case SaltLowEntropy:
+		return "Salt has too little entropy"
+	case TooWeak:
+		return "Hash is weaker than the policy floor"
+	case UnknownKey:
+		return "Pepper key is unknown"
+	default:
+		return "Unknown error code"
+	}
+}
+
+var (
+	ErrPwdTooShort       = errors.New(ErrorMessage(PwdTooShort))
+	ErrPwdTooLong        = errors.New(ErrorMessage(PwdTooLong))
+	ErrSaltTooShort      = errors.New(ErrorMessage(SaltTooShort))
+	ErrSaltTooLong       = errors.New(ErrorMessage(SaltTooLong))
+	ErrSecretTooShort    = errors.New(ErrorMessage(SecretTooShort))
+	ErrSecretTooLong     = errors.New(ErrorMessage(SecretTooLong))
+	ErrTimeTooSmall      = errors.New(ErrorMessage(TimeTooSmall))
+	ErrTimeTooLarge      = errors.New(ErrorMessage(TimeTooLarge))
+	ErrMemoryTooLittle   = errors.New(ErrorMessage(MemoryTooLittle))
+	ErrMemoryTooMuch     = errors.New(ErrorMessage(MemoryTooMuch))
+	ErrPwdPtrMismatch    = errors.New(ErrorMessage(PwdPtrMismatch))
+	ErrSaltPtrMismatch   = errors.New(ErrorMessage(SaltPtrMismatch))
+	ErrSecretPtrMismatch = errors.New(ErrorMessage(SecretPtrMismatch))
+	ErrIncorrectType     = errors.New(ErrorMessage(IncorrectType))
+	ErrThreadsTooFew     = errors.New(ErrorMessage(ThreadsTooFew))
+	ErrThreadsTooMany    = errors.New(ErrorMessage(ThreadsTooMany))
+	ErrDecoding          = errors.New(ErrorMessage(DecodingFail))
+	ErrMismatch          = errors.New(ErrorMessage(VerifyMismatch))
+	ErrAdTooShort        = errors.New(ErrorMessage(AdTooShort))
+	ErrAdTooLong         = errors.New(ErrorMessage(AdTooLong))
+	ErrIncorrectVersion  = errors.New(ErrorMessage(IncorrectVersion))
+	ErrSaltLowEntropy    = errors.New(ErrorMessage(SaltLowEntropy))
+	ErrTooWeak           = errors.New(ErrorMessage(TooWeak))
+	ErrUnknownKey        = errors.New(ErrorMessage(UnknownKey))
+	ErrUnknownErrorCode  = errors.New(ErrorMessage(-1))
+)
+
+// Sentinel returns the sentinel error for an error code, or nil for Ok.
+func Sentinel(errorCode int) error {
+	switch errorCode {
+	case Ok:
+		return nil
+	case PwdTooShort:
+		return ErrPwdTooShort
+	case PwdTooLong:
+		return ErrPwdTooLong
+	case SaltTooShort:
+		return ErrSaltTooShort
+	case SaltTooLong:
+		return ErrSaltTooLong
+	case SecretTooShort:
+		return ErrSecretTooShort
+	case SecretTooLong:
+		return ErrSecretTooLong
+	case TimeTooSmall:
+		return ErrTimeTooSmall
+	case TimeTooLarge:
+		return ErrTimeTooLarge
+	case MemoryTooLittle:
+		return ErrMemoryTooLittle
+	case MemoryTooMuch:
+		return ErrMemoryTooMuch
+	case PwdPtrMismatch:
+		return ErrPwdPtrMismatch
+	case SaltPtrMismatch:
+		return ErrSaltPtrMismatch
+	case SecretPtrMismatch:
+		return ErrSecretPtrMismatch
+	case IncorrectType:
+		return ErrIncorrectType
+	case ThreadsTooFew:
+		return ErrThreadsTooFew
+	case ThreadsTooMany:
+		return ErrThreadsTooMany
+	case DecodingFail:
+		return ErrDecoding
+	case VerifyMismatch:
+		return ErrMismatch
+	case AdTooShort:
+		return ErrAdTooShort
+	case AdTooLong:
+		return ErrAdTooLong
+	case IncorrectVersion:
+		return ErrIncorrectVersion
+	case SaltLowEntropy:
+		return ErrSaltLowEntropy
+	case TooWeak:
+		return ErrTooWeak
+	case UnknownKey:
+		return ErrUnknownKey
+	default:
+		return ErrUnknownErrorCode
+	}
+}
+
+// Error is an error code together with the field that caused it. It matches
+// the sentinel error of its code with errors.Is.
+type Error struct {
+	Code  int    // error code
+	Field string // offending field, empty when not tied to one
+	Err   error  // underlying cause, if any
+}
+
+// NewError returns the Error of code on field.
+func NewError(code int, field string) *Error {
+	return &Error{Code: code, Field: field}
+}
+
+// DecodingError returns a DecodingFail Error on field caused by err.
+func DecodingError(field string, err error) *Error {
+	return &Error{Code: DecodingFail, Field: field, Err: err}
+}
+
+func (e *Error) Error() string {
+	var out strings.Builder
+	if e.Field != "" {
+		out.WriteString(e.Field)
+		out.WriteString(": ")
+	}
+	out.WriteString(ErrorMessage(e.Code))
+	if e.Err != nil {
+		out.WriteString(": ")
+		out.WriteString(e.Err.Error())
+	}
+
+	return out.String()
+}
+
+func (e *Error) Is(target error) bool {
+	return target == Sentinel(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errors holds several violations at once.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package kdf_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+)
+
+func TestError(t *testing.T) {
+	err := error(kdf.DecodingError("salt", io.ErrUnexpectedEOF))
+	if !errors.Is(err, kdf.ErrDecoding) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got: %v, want: %v and %v", err, kdf.ErrDecoding, io.ErrUnexpectedEOF)
+	}
+	if got, want := err.Error(), "salt: Decoding failed: unexpected EOF"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+
+	var e *kdf.Error
+	err = kdf.Errors{kdf.NewError(kdf.TimeTooSmall, "t"), kdf.NewError(kdf.SaltTooShort, "salt")}
+	if !errors.Is(err, kdf.ErrSaltTooShort) || !errors.As(err, &e) || e.Field != "t" {
+		t.Errorf("got: %v, %#v", err, e)
+	}
+
+	for code := kdf.PwdTooShort; code <= kdf.UnknownKey; code++ {
+		if got := kdf.Sentinel(code); got == nil || got.Error() != kdf.ErrorMessage(code) {
+			t.Errorf("code %d - got: %v", code, got)
+		}
+	}
+	if kdf.Sentinel(kdf.Ok) != nil || kdf.Sentinel(-1) != kdf.ErrUnknownErrorCode {
+		t.Errorf("expected nil for Ok and ErrUnknownErrorCode for -1")
+	}
+}
@@ -0,0 +1,65 @@
+package kdf
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+const (
+	MinRandomSaltLength uint32 = 16 // shortest salt in bytes GenerateSalt accepts
+	DefaultSaltLength   uint32 = 16 // salt length in bytes used when none is given
+)
+
+// GenerateSalt reads a salt of length bytes from r, or from crypto/rand when
+// r is nil, and checks it with CheckSalt.
+func GenerateSalt(r io.Reader, length uint32) (string, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+
+	if length < MinRandomSaltLength {
+		return "", NewError(SaltTooShort, "SaltLen")
+	}
+
+	salt := make([]byte, length)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return "", err
+	}
+
+	ret := string(salt)
+	if err := CheckSalt(ret); err != nil {
+		return "", err
+	}
+
+	return ret, nil
+}
+
+// CheckSalt rejects salts shorter than MinRandomSaltLength and salts with so
+// few distinct bytes that they cannot come from a working random source, such
+// as a constant or a short repeating pattern.
+func CheckSalt(salt string) error {
+	if uint32(len(salt)) < MinRandomSaltLength {
+		return NewError(SaltTooShort, "Salt")
+	}
+
+	var seen [256]bool
+	distinct := 0
+	for i := 0; i < len(salt); i++ {
+		if !seen[salt[i]] {
+			seen[salt[i]] = true
+			distinct++
+		}
+	}
+
+	// A uniformly random salt has nearly all of its bytes distinct up to
+	// about 64 bytes, half of that is far out of reach by chance
+	want := len(salt) / 2
+	if want > 64 {
+		want = 64
+	}
+	if distinct < want {
+		return NewError(SaltLowEntropy, "Salt")
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package kdf_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+)
+
+func TestGenerateSalt(t *testing.T) {
+	salt, err := kdf.GenerateSalt(nil, kdf.DefaultSaltLength)
+	if err != nil || uint32(len(salt)) != kdf.DefaultSaltLength {
+		t.Errorf("got: %x, %v", salt, err)
+	}
+
+	if _, err := kdf.GenerateSalt(nil, kdf.MinRandomSaltLength-1); !errors.Is(err, kdf.ErrSaltTooShort) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrSaltTooShort)
+	}
+	if _, err := kdf.GenerateSalt(bytes.NewReader(make([]byte, 16)), 16); !errors.Is(err, kdf.ErrSaltLowEntropy) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrSaltLowEntropy)
+	}
+}
@@ -0,0 +1,172 @@
+package pbkdf2
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+// ab64 is the adapted base64 of passlib, whose alphabet has . instead of +.
+var ab64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding).Strict()
+
+// decoded is an encoded hash split into its parts.
+type decoded struct {
+	Digest     string
+	Iterations uint32
+	Format     Format
+	Salt       []byte // raw salt, or the salt text of the Django format
+	Hash       []byte
+}
+
+// digestOf returns the digest and format of id.
+func digestOf(id string, leadingDollar bool) (string, Format, bool) {
+	switch {
+	case !leadingDollar && id == DjangoIDSHA1:
+		return DigestSHA1, FormatDjango, true
+	case !leadingDollar && id == DjangoIDSHA256:
+		return DigestSHA256, FormatDjango, true
+	case leadingDollar && id == PasslibIDSHA1:
+		return DigestSHA1, FormatPasslib, true
+	case leadingDollar && strings.HasPrefix(id, "pbkdf2-"):
+		digest := strings.TrimPrefix(id, "pbkdf2-")
+		_, ok := digests[digest]
+		return digest, FormatPHC, ok
+	}
+
+	return "", 0, false
+}
+
+// decode reads a hash in any of the formats, telling the PHC and passlib
+// strings sharing an id apart by the i= of the PHC one.
+func decode(encoded string) (decoded, error) {
+	s := strings.TrimPrefix(encoded, "$")
+	fields := strings.Split(s, "$")
+
+	digest, format, ok := digestOf(fields[0], len(s) != len(encoded))
+	if !ok {
+		return decoded{}, kdf.NewError(kdf.IncorrectType, "id")
+	}
+	if format == FormatPHC && len(fields) > 1 && !strings.Contains(fields[1], "=") {
+		format = FormatPasslib
+	}
+
+	d := decoded{Digest: digest, Format: format}
+	var err error
+	switch format {
+	case FormatPHC:
+		err = d.decodePHC(encoded)
+	default:
+		err = d.decodeModular(fields)
+	}
+	if err != nil {
+		return decoded{}, err
+	}
+
+	if d.Iterations == 0 {
+		return decoded{}, kdf.DecodingError("i", nil)
+	}
+	if len(d.Hash) == 0 {
+		return decoded{}, kdf.DecodingError("hash", nil)
+	}
+
+	return d, nil
+}
+
+func (d *decoded) decodePHC(encoded string) error {
+	h, err := phc.Parse(encoded)
+	if err != nil {
+		return kdf.DecodingError("", err)
+	}
+	if h.HasVersion {
+		return kdf.DecodingError("v", nil)
+	}
+
+	hash, err := phc.B64.DecodeString(h.Hash)
+	if err != nil {
+		return kdf.DecodingError("hash", err)
+	}
+	d.Hash = hash
+
+	if d.Salt, err = phc.B64.DecodeString(h.Salt); err != nil || h.Salt == "" {
+		return kdf.DecodingError("salt", err)
+	}
+
+	for _, param := range h.Params {
+		n, err := phc.ParseUint(param.Value, 32)
+		if err != nil {
+			return kdf.DecodingError(param.Name, err)
+		}
+
+		switch param.Name {
+		case "i":
+			d.Iterations = uint32(n)
+		case "l":
+			// Only the length of the hash itself is supported
+			if n != uint64(len(hash)) {
+				return kdf.DecodingError("l", nil)
+			}
+		default:
+			return kdf.DecodingError(param.Name, nil)
+		}
+	}
+
+	return nil
+}
+
+// decodeModular reads the Django and passlib formats, id$i$salt$hash.
+func (d *decoded) decodeModular(fields []string) error {
+	if len(fields) != 4 {
+		return kdf.DecodingError("", phc.ErrInvalid)
+	}
+
+	n, err := phc.ParseUint(fields[1], 32)
+	if err != nil {
+		return kdf.DecodingError("i", err)
+	}
+	d.Iterations = uint32(n)
+
+	if fields[2] == "" {
+		return kdf.DecodingError("salt", nil)
+	}
+	if d.Format == FormatDjango {
+		d.Salt = []byte(fields[2])
+		d.Hash, err = base64.StdEncoding.Strict().DecodeString(fields[3])
+	} else {
+		if d.Salt, err = ab64.DecodeString(fields[2]); err != nil {
+			return kdf.DecodingError("salt", err)
+		}
+		d.Hash, err = ab64.DecodeString(fields[3])
+	}
+	if err != nil {
+		return kdf.DecodingError("hash", err)
+	}
+
+	return nil
+}
+
+func (d decoded) encode() string {
+	switch d.Format {
+	case FormatDjango:
+		return "pbkdf2_" + d.Digest + "$" + strconv.FormatUint(uint64(d.Iterations), 10) + "$" +
+			string(d.Salt) + "$" + base64.StdEncoding.EncodeToString(d.Hash)
+	case FormatPasslib:
+		id := "pbkdf2-" + d.Digest
+		if d.Digest == DigestSHA1 {
+			id = PasslibIDSHA1
+		}
+		return "$" + id + "$" + strconv.FormatUint(uint64(d.Iterations), 10) + "$" +
+			ab64.EncodeToString(d.Salt) + "$" + ab64.EncodeToString(d.Hash)
+	}
+
+	h := phc.Hash{
+		ID:     "pbkdf2-" + d.Digest,
+		Params: []phc.Param{{Name: "i", Value: strconv.FormatUint(uint64(d.Iterations), 10)}},
+		Salt:   phc.B64.EncodeToString(d.Salt),
+		Hash:   phc.B64.EncodeToString(d.Hash),
+	}
+
+	return h.String()
+}
@@ -0,0 +1,270 @@
+// Package pbkdf2 hashes passwords with PBKDF2 using HMAC-SHA-1, HMAC-SHA-256
+// or HMAC-SHA-512, and reads and writes the hashes of Django
+//
+//	pbkdf2_sha256$<iterations>$<salt>$<hash>
+//
+// of passlib
+//
+//	$pbkdf2-sha512$<iterations>$<salt>$<hash>
+//
+// and the PHC string format
+//
+//	$pbkdf2-sha256$i=<iterations>$<salt>$<hash>
+//
+// Being built on approved primitives only, and on kdf for its errors and
+// salts, it is the one algorithm of the crypt registry in FIPS builds.
+package pbkdf2
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"hash"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	DigestSHA1   = "sha1"
+	DigestSHA256 = "sha256"
+	DigestSHA512 = "sha512"
+)
+
+// Hash ids, the Django ones not starting with $
+const (
+	IDSHA1         = "pbkdf2-sha1"
+	IDSHA256       = "pbkdf2-sha256"
+	IDSHA512       = "pbkdf2-sha512"
+	PasslibIDSHA1  = "pbkdf2"
+	DjangoIDSHA1   = "pbkdf2_sha1"
+	DjangoIDSHA256 = "pbkdf2_sha256"
+)
+
+const (
+	MinIterations        = 1000     // fewest iterations of new hashes, after NIST SP 800-132
+	DefaultMaxIterations = 10000000 // most iterations of verified hashes
+)
+
+// DefaultIterations are the iterations of new hashes per digest, after the
+// OWASP password storage recommendations.
+var DefaultIterations = map[string]uint32{
+	DigestSHA1:   1300000,
+	DigestSHA256: 600000,
+	DigestSHA512: 210000,
+}
+
+var digests = map[string]func() hash.Hash{
+	DigestSHA1:   sha1.New,
+	DigestSHA256: sha256.New,
+	DigestSHA512: sha512.New,
+}
+
+// Limits bounds the hashes a Hasher verifies, which may come from untrusted
+// storage.
+type Limits struct {
+	MaxSaltLen uint32 // longest salt in bytes
+	MaxHashLen uint32 // longest hash in bytes
+	MaxPwdLen  uint32 // longest password in bytes
+}
+
+// DefaultLimits are the limits of the zero fields of Limits.
+var DefaultLimits = Limits{
+	MaxSaltLen: 128,
+	MaxHashLen: 128,
+	MaxPwdLen:  4096,
+}
+
+// WithDefaults returns l with its zero fields set from DefaultLimits.
+func (l Limits) WithDefaults() Limits {
+	if l.MaxSaltLen == 0 {
+		l.MaxSaltLen = DefaultLimits.MaxSaltLen
+	}
+	if l.MaxHashLen == 0 {
+		l.MaxHashLen = DefaultLimits.MaxHashLen
+	}
+	if l.MaxPwdLen == 0 {
+		l.MaxPwdLen = DefaultLimits.MaxPwdLen
+	}
+
+	return l
+}
+
+type Format int
+
+const (
+	FormatPHC     Format = iota // $pbkdf2-<digest>$i=...
+	FormatPasslib               // $pbkdf2-<digest>$<iterations>..., $pbkdf2$ for SHA-1
+	FormatDjango                // pbkdf2_<digest>$<iterations>..., SHA-1 and SHA-256 only
+)
+
+// ValidateInputs checks the digest, iterations and salt of a new hash.
+func ValidateInputs(digest string, iterations uint32, salt []byte) error {
+	switch {
+	case digests[digest] == nil:
+		return kdf.NewError(kdf.IncorrectType, "Digest")
+	case iterations < MinIterations:
+		return kdf.NewError(kdf.TimeTooSmall, "i")
+	case iterations > DefaultMaxIterations:
+		return kdf.NewError(kdf.TimeTooLarge, "i")
+	case len(salt) < int(MinSaltLength):
+		return kdf.NewError(kdf.SaltTooShort, "salt")
+	}
+
+	return nil
+}
+
+// Hasher hashes passwords with PBKDF2. Its methods make it a crypt.Hasher
+// that verifies hashes of every digest and format. Hashes are as long as the
+// digest.
+type Hasher struct {
+	Digest     string // DigestSHA256 if empty
+	Iterations uint32 // DefaultIterations of Digest if zero
+	SaltLen    uint32 // salt length in bytes, kdf.DefaultSaltLength if zero
+	Format     Format
+
+	// MinIterations makes hashes with fewer iterations fail verification
+	// with kdf.ErrTooWeak, MaxIterations with kdf.ErrTimeTooLarge,
+	// DefaultMaxIterations if zero.
+	MinIterations uint32
+	MaxIterations uint32
+
+	Limits Limits // password, salt and hash lengths of verified hashes
+}
+
+func (h Hasher) digest() string {
+	if h.Digest == "" {
+		return DigestSHA256
+	}
+
+	return h.Digest
+}
+
+func (h Hasher) iterations() uint32 {
+	if h.Iterations == 0 {
+		return DefaultIterations[h.digest()]
+	}
+
+	return h.Iterations
+}
+
+func (h Hasher) saltLen() uint32 {
+	saltLen := h.SaltLen
+	if saltLen == 0 {
+		saltLen = kdf.DefaultSaltLength
+	}
+	if h.Format == FormatDjango {
+		// Salts of the Django format are encoded
+		saltLen = uint32(ab64.EncodedLen(int(saltLen)))
+	}
+
+	return saltLen
+}
+
+// ID returns the id of new hashes, PasslibIDSHA1 or one of the Django ids
+// in those formats.
+func (h Hasher) ID() string {
+	switch {
+	case h.Format == FormatDjango:
+		return "pbkdf2_" + h.digest()
+	case h.Format == FormatPasslib && h.digest() == DigestSHA1:
+		return PasslibIDSHA1
+	}
+
+	return "pbkdf2-" + h.digest()
+}
+
+// Hash hashes pwd with a random salt.
+func (h Hasher) Hash(pwd string) (string, error) {
+	d := decoded{Digest: h.digest(), Iterations: h.iterations(), Format: h.Format}
+	if d.Format == FormatDjango && d.Digest != DigestSHA1 && d.Digest != DigestSHA256 {
+		return "", kdf.NewError(kdf.IncorrectType, "Digest")
+	}
+
+	saltLen := h.SaltLen
+	if saltLen == 0 {
+		saltLen = kdf.DefaultSaltLength
+	}
+	salt, err := kdf.GenerateSalt(nil, saltLen)
+	if err != nil {
+		return "", err
+	}
+	d.Salt = []byte(salt)
+	if d.Format == FormatDjango {
+		d.Salt = []byte(ab64.EncodeToString(d.Salt))
+	}
+
+	if err = ValidateInputs(d.Digest, d.Iterations, d.Salt); err != nil {
+		return "", err
+	}
+	d.Hash = key(d.Digest, pwd, d.Salt, d.Iterations)
+
+	return d.encode(), nil
+}
+
+func key(digest, pwd string, salt []byte, iterations uint32) []byte {
+	h := digests[digest]
+	return pbkdf2.Key([]byte(pwd), salt, int(iterations), h().Size(), h)
+}
+
+// check enforces the iteration policy and limits of h on a decoded hash
+// before computing it.
+func (h Hasher) check(d decoded, pwdLen int) error {
+	limits := h.Limits.WithDefaults()
+	maxIterations := h.MaxIterations
+	if maxIterations == 0 {
+		maxIterations = DefaultMaxIterations
+	}
+
+	switch {
+	case int64(pwdLen) > int64(limits.MaxPwdLen):
+		return kdf.NewError(kdf.PwdTooLong, "Pwd")
+	case d.Iterations > maxIterations:
+		return kdf.NewError(kdf.TimeTooLarge, "i")
+	case d.Iterations < h.MinIterations:
+		return kdf.NewError(kdf.TooWeak, "i")
+	case uint64(len(d.Salt)) > uint64(limits.MaxSaltLen):
+		return kdf.NewError(kdf.SaltTooLong, "salt")
+	case uint32(len(d.Salt)) < minVerifySaltLength:
+		return kdf.NewError(kdf.SaltTooShort, "salt")
+	case uint64(len(d.Hash)) > uint64(limits.MaxHashLen):
+		return kdf.NewError(kdf.SecretTooLong, "hash")
+	}
+
+	return nil
+}
+
+// Verify returns nil if pwd matches encoded, a hash in any of the formats,
+// after checking encoded against the iteration policy and limits of h.
+func (h Hasher) Verify(encoded, pwd string) error {
+	d, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+	if err = h.check(d, len(pwd)); err != nil {
+		return err
+	}
+
+	sum := key(d.Digest, pwd, d.Salt, d.Iterations)
+	if len(sum) == len(d.Hash) && subtle.ConstantTimeCompare(sum, d.Hash) == 1 {
+		return nil
+	}
+
+	return kdf.NewError(kdf.VerifyMismatch, "")
+}
+
+// NeedsRehash reports whether encoded has fewer iterations than new hashes,
+// or another digest, salt length or format. Hashes with more iterations are
+// kept, so that upgrading never weakens them.
+func (h Hasher) NeedsRehash(encoded string) (bool, error) {
+	d, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	return d.Digest != h.digest() ||
+		d.Iterations < h.iterations() ||
+		d.Format != h.Format ||
+		uint32(len(d.Salt)) != h.saltLen(), nil
+}
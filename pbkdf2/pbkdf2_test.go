@@ -0,0 +1,165 @@
+package pbkdf2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/pbkdf2"
+)
+
+// Hashes of RFC 6070 and of Python's hashlib in each format, those with salts
+// under 16 bytes failing verification in FIPS builds
+var testVectors = []struct {
+	password  string
+	encoded   string
+	shortSalt bool
+}{
+	{"password", "$pbkdf2$4096$c2FsdA$SwB5AbdlSJq.rUnZJvch0GWkKcE", true},
+	{"password", "$pbkdf2-sha1$i=4096$c2FsdA$SwB5AbdlSJq+rUnZJvch0GWkKcE", true},
+	{"password", "pbkdf2_sha1$4096$salt$SwB5AbdlSJq+rUnZJvch0GWkKcE=", true},
+	{"lètmein", "pbkdf2_sha256$1000$seasalt$JgZryXe2Ga8ysg6XbzkLpTdyPQrHqsinbL9BnnhgX4A=", true},
+	{"lètmein", "$pbkdf2-sha256$i=1000,l=32$c2Vhc2FsdA$JgZryXe2Ga8ysg6XbzkLpTdyPQrHqsinbL9BnnhgX4A", true},
+	{"password", "$pbkdf2$4096$AAECAwQFBgcICQoLDA0ODw$531aYHrF581Skow4E0gCWLw/Ibo", false},
+	{"password", "$pbkdf2-sha512$1000$AAECAwQFBgcICQoLDA0ODw$x05AgND7tB/uWGjA/2D9dayuJjghWYfl/1T46uIRM5ta0a9uOHvBLdOnC7blqQEIFBxfCONToumEQ5pDM8Qtbg", false},
+	{"password", "$pbkdf2-sha512$i=1000$AAECAwQFBgcICQoLDA0ODw$x05AgND7tB/uWGjA/2D9dayuJjghWYfl/1T46uIRM5ta0a9uOHvBLdOnC7blqQEIFBxfCONToumEQ5pDM8Qtbg", false},
+}
+
+func TestVectors(t *testing.T) {
+	h := pbkdf2.Hasher{}
+	for i, v := range testVectors {
+		if v.shortSalt && fips {
+			if err := h.Verify(v.encoded, v.password); !errors.Is(err, kdf.ErrSaltTooShort) {
+				t.Errorf("Test %d - got: %v, want: %v", i, err, kdf.ErrSaltTooShort)
+			}
+			continue
+		}
+
+		if err := h.Verify(v.encoded, v.password); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+		if err := h.Verify(v.encoded, v.password+"x"); !errors.Is(err, kdf.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, kdf.ErrMismatch)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		hasher pbkdf2.Hasher
+		prefix string
+	}{
+		{pbkdf2.Hasher{Iterations: 1000}, "$pbkdf2-sha256$i=1000$"},
+		{pbkdf2.Hasher{Digest: pbkdf2.DigestSHA512, Iterations: 1000}, "$pbkdf2-sha512$i=1000$"},
+		{pbkdf2.Hasher{Digest: pbkdf2.DigestSHA1, Iterations: 1000, Format: pbkdf2.FormatPasslib}, "$pbkdf2$1000$"},
+		{pbkdf2.Hasher{Digest: pbkdf2.DigestSHA512, Iterations: 1000, Format: pbkdf2.FormatPasslib}, "$pbkdf2-sha512$1000$"},
+		{pbkdf2.Hasher{Iterations: 1000, Format: pbkdf2.FormatDjango}, "pbkdf2_sha256$1000$"},
+	}
+	for i, test := range tests {
+		encoded, err := test.hasher.Hash("password")
+		if err != nil {
+			t.Fatalf("failed to hash: %v", err)
+		}
+		if !strings.HasPrefix(encoded, test.prefix) || !strings.HasPrefix(strings.TrimPrefix(encoded, "$"), test.hasher.ID()+"$") {
+			t.Errorf("Test %d - got: %s, want prefix: %s", i, encoded, test.prefix)
+		}
+
+		if err := test.hasher.Verify(encoded, "password"); err != nil {
+			t.Errorf("Test %d - got: %v, want: nil", i, err)
+		}
+		needsRehash, err := test.hasher.NeedsRehash(encoded)
+		if err != nil || needsRehash {
+			t.Errorf("Test %d - got: %v, %v, want: false, nil", i, needsRehash, err)
+		}
+	}
+
+	if _, err := (pbkdf2.Hasher{Digest: pbkdf2.DigestSHA512, Format: pbkdf2.FormatDjango}).Hash("password"); !errors.Is(err, kdf.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrIncorrectType)
+	}
+	if _, err := (pbkdf2.Hasher{Iterations: 999}).Hash("password"); !errors.Is(err, kdf.ErrTimeTooSmall) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrTimeTooSmall)
+	}
+	if _, err := (pbkdf2.Hasher{Digest: "md5"}).Hash("password"); !errors.Is(err, kdf.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrIncorrectType)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	h := pbkdf2.Hasher{Iterations: 1000}
+	encoded, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	outdated := []pbkdf2.Hasher{h, h, h, h, {}}
+	outdated[0].Digest = pbkdf2.DigestSHA512
+	outdated[1].Iterations = 2000
+	outdated[2].SaltLen = 32
+	outdated[3].Format = pbkdf2.FormatPasslib
+	for i, h := range outdated {
+		needsRehash, err := h.NeedsRehash(encoded)
+		if err != nil || !needsRehash {
+			t.Errorf("Hasher %d - got: %v, %v, want: true, nil", i, needsRehash, err)
+		}
+	}
+
+	// More iterations than the policy are kept
+	costly, err := pbkdf2.Hasher{Iterations: 2000}.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if needsRehash, err := h.NeedsRehash(costly); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+}
+
+func TestIterationPolicy(t *testing.T) {
+	tests := []struct {
+		hasher pbkdf2.Hasher
+		want   error
+	}{
+		{pbkdf2.Hasher{}, nil},
+		{pbkdf2.Hasher{MinIterations: 4096}, nil},
+		{pbkdf2.Hasher{MinIterations: 4097}, kdf.ErrTooWeak},
+		{pbkdf2.Hasher{MaxIterations: 4095}, kdf.ErrTimeTooLarge},
+		{pbkdf2.Hasher{Limits: pbkdf2.Limits{MaxPwdLen: 4}}, kdf.ErrPwdTooLong},
+		{pbkdf2.Hasher{Limits: pbkdf2.Limits{MaxHashLen: 16}}, kdf.ErrSecretTooLong},
+	}
+	for i, test := range tests {
+		if err := test.hasher.Verify(testVectors[5].encoded, "password"); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+
+	if err := (pbkdf2.Hasher{}).Verify("$pbkdf2-sha256$i=10000001$c2FsdA$aGFzaA", "password"); !errors.Is(err, kdf.ErrTimeTooLarge) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrTimeTooLarge)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    error
+	}{
+		{"$pbkdf2_sha256$1000$salt$aGFzaA==", kdf.ErrIncorrectType},
+		{"pbkdf2-sha256$1000$salt$aGFzaA", kdf.ErrIncorrectType},
+		{"pbkdf2_sha512$1000$salt$aGFzaA==", kdf.ErrIncorrectType},
+		{"$pbkdf2-md5$i=1000$c2FsdA$aGFzaA", kdf.ErrIncorrectType},
+		{"pbkdf2_sha256$1000$salt", kdf.ErrDecoding},
+		{"pbkdf2_sha256$x$salt$aGFzaA==", kdf.ErrDecoding},
+		{"pbkdf2_sha256$0$salt$aGFzaA==", kdf.ErrDecoding},
+		{"pbkdf2_sha256$1000$$aGFzaA==", kdf.ErrDecoding},
+		{"pbkdf2_sha256$1000$salt$aGFzaA", kdf.ErrDecoding},
+		{"$pbkdf2$1000$c2FsdA$a+FzaA", kdf.ErrDecoding},
+		{"$pbkdf2-sha256$i=1000,l=16$c2FsdA$aGFzaA", kdf.ErrDecoding},
+		{"$pbkdf2-sha256$i=1000,r=8$c2FsdA$aGFzaA", kdf.ErrDecoding},
+		{"$pbkdf2-sha256$i=1000$c2FsdA", kdf.ErrDecoding},
+		{"$pbkdf2-sha256$v=1$i=1000$c2FsdA$aGFzaA", kdf.ErrDecoding},
+	}
+	for i, test := range tests {
+		if err := (pbkdf2.Hasher{}).Verify(test.encoded, "password"); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build fips
+// +build fips
+
+package pbkdf2
+
+// MinSaltLength is the shortest salt in bytes of new and verified hashes, the
+// 128 bits NIST SP 800-132 requires. Stored hashes with shorter salts fail
+// verification with kdf.ErrSaltTooShort and have to be reset.
+const MinSaltLength uint32 = 16
+
+const minVerifySaltLength = MinSaltLength
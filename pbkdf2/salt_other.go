@@ -0,0 +1,10 @@
+//go:build !fips
+// +build !fips
+
+package pbkdf2
+
+// MinSaltLength is the shortest salt in bytes of new hashes, that of Argon2.
+// Stored hashes of any salt length verify.
+const MinSaltLength uint32 = 8
+
+const minVerifySaltLength uint32 = 0
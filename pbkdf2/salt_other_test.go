@@ -0,0 +1,23 @@
+//go:build !fips
+// +build !fips
+
+package pbkdf2_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/pbkdf2"
+)
+
+const fips = false
+
+func TestMinSaltLength(t *testing.T) {
+	if err := pbkdf2.ValidateInputs(pbkdf2.DigestSHA256, 1000, []byte("0123456")); !errors.Is(err, kdf.ErrSaltTooShort) {
+		t.Errorf("got: %v, want: %v", err, kdf.ErrSaltTooShort)
+	}
+	if err := pbkdf2.ValidateInputs(pbkdf2.DigestSHA256, 1000, []byte("01234567")); err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package pepper manages versioned server-side secrets mixed into password
+// hashes. The pepper is kept out of the database, and each hash records the id
+// of the key it was made with so that keys can be rotated.
+package pepper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	MaxIDLength  = 8  // Maximum key id length in bytes, the limit of the PHC keyid parameter
+	MinKeyLength = 16 // Minimum pepper length in bytes
+)
+
+var (
+	ErrUnknownKey = errors.New("pepper: unknown key id")
+	ErrInvalidID  = errors.New("pepper: invalid key id")
+	ErrShortKey   = errors.New("pepper: key is too short")
+	ErrNoCurrent  = errors.New("pepper: no current key")
+)
+
+// KeyProvider fetches the pepper of a key id, failing with an error matching
+// ErrUnknownKey when it has none.
+type KeyProvider interface {
+	Key(id string) ([]byte, error)
+}
+
+// ValidateID reports whether id can be recorded in a hash and used as a file
+// or environment variable name: 1 to MaxIDLength letters, digits, '-' or '_'.
+func ValidateID(id string) error {
+	if id == "" || len(id) > MaxIDLength {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return fmt.Errorf("%w: %q", ErrInvalidID, id)
+		}
+	}
+
+	return nil
+}
+
+// Static is a KeyProvider of keys held in memory, such as a stand-in for a
+// key management service in tests.
+type Static map[string][]byte
+
+func (s Static) Key(id string) ([]byte, error) {
+	key, ok := s[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	return key, nil
+}
+
+// Dir is a KeyProvider reading the key of id from the file named id in the
+// directory, such as a mounted secret volume. The file content is the key.
+type Dir string
+
+func (d Dir) Key(id string) ([]byte, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(filepath.Join(string(d), id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Env is a KeyProvider reading the key of id, in padded standard base64, from
+// the environment variable named by the prefix followed by id.
+type Env string
+
+func (e Env) Key(id string) ([]byte, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	value, ok := os.LookupEnv(string(e) + id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("pepper: key %q: %w", id, err)
+	}
+
+	return key, nil
+}
+
+// Keyring holds the versions of a pepper: the current key new hashes are
+// made with and the retired keys that old hashes may still be verified with
+// until they are rehashed. Keys are fetched from Provider on first use and
+// cached, ids that are neither current nor retired are never fetched.
+type Keyring struct {
+	Provider KeyProvider
+	Current  string   // id of the key for new hashes
+	Retired  []string // ids of keys old hashes may still use
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+// Key returns a copy of the key of id, which must be current or retired.
+func (k *Keyring) Key(id string) ([]byte, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	known := id == k.Current
+	for _, r := range k.Retired {
+		known = known || r == id
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.cache[id]
+	if !ok {
+		var err error
+		if key, err = k.Provider.Key(id); err != nil {
+			return nil, err
+		}
+		if len(key) < MinKeyLength {
+			return nil, fmt.Errorf("%w: %q", ErrShortKey, id)
+		}
+
+		if k.cache == nil {
+			k.cache = make(map[string][]byte)
+		}
+		key = append([]byte(nil), key...)
+		k.cache[id] = key
+	}
+
+	return append([]byte(nil), key...), nil
+}
+
+// CurrentKey returns the id and a copy of the current key.
+func (k *Keyring) CurrentKey() (string, []byte, error) {
+	if k.Current == "" {
+		return "", nil, ErrNoCurrent
+	}
+
+	key, err := k.Key(k.Current)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return k.Current, key, nil
+}
+
+// IsRetired reports whether hashes made with id should be rehashed with the
+// current key.
+func (k *Keyring) IsRetired(id string) bool {
+	return id != k.Current
+}
+
+// Prehash returns the base64-encoded HMAC-SHA256 of password keyed with the
+// pepper, for password hashes that have no secret input of their own. The
+// result has no NUL bytes and a fixed length of 44 bytes.
+func Prehash(key, password []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(password)
+
+	sum := mac.Sum(nil)
+	ret := make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+	base64.StdEncoding.Encode(ret, sum)
+	return ret
+}
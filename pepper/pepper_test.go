@@ -0,0 +1,118 @@
+package pepper_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/pepper"
+)
+
+func TestValidateID(t *testing.T) {
+	for _, id := range []string{"1", "k1", "2022-01", "a_B"} {
+		if err := pepper.ValidateID(id); err != nil {
+			t.Errorf("%q - got: %v, want: nil", id, err)
+		}
+	}
+	for _, id := range []string{"", "123456789", "../k", "k 1", "k$"} {
+		if err := pepper.ValidateID(id); !errors.Is(err, pepper.ErrInvalidID) {
+			t.Errorf("%q - got: %v, want: %v", id, err, pepper.ErrInvalidID)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "k1"), []byte("0123456789abcdef"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := pepper.Dir(dir).Key("k1")
+	if err != nil || string(key) != "0123456789abcdef" {
+		t.Errorf("got: %q, %v", key, err)
+	}
+	if _, err := pepper.Dir(dir).Key("k2"); !errors.Is(err, pepper.ErrUnknownKey) {
+		t.Errorf("got: %v, want: %v", err, pepper.ErrUnknownKey)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("TEST_PEPPER_k1", "MDEyMzQ1Njc4OWFiY2RlZg==")
+	t.Setenv("TEST_PEPPER_k2", "not base64")
+
+	key, err := pepper.Env("TEST_PEPPER_").Key("k1")
+	if err != nil || string(key) != "0123456789abcdef" {
+		t.Errorf("got: %q, %v", key, err)
+	}
+	if _, err := pepper.Env("TEST_PEPPER_").Key("k2"); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+	if _, err := pepper.Env("TEST_PEPPER_").Key("k3"); !errors.Is(err, pepper.ErrUnknownKey) {
+		t.Errorf("got: %v, want: %v", err, pepper.ErrUnknownKey)
+	}
+}
+
+type countingProvider struct {
+	pepper.Static
+	calls int
+}
+
+func (c *countingProvider) Key(id string) ([]byte, error) {
+	c.calls++
+	return c.Static.Key(id)
+}
+
+func TestKeyring(t *testing.T) {
+	provider := &countingProvider{Static: pepper.Static{
+		"k1":    []byte("0123456789abcdef"),
+		"k2":    []byte("fedcba9876543210"),
+		"k3":    []byte("0123456789abcdef"),
+		"short": []byte("short"),
+	}}
+	ring := &pepper.Keyring{Provider: provider, Current: "k2", Retired: []string{"k1", "short"}}
+
+	id, key, err := ring.CurrentKey()
+	if err != nil || id != "k2" || string(key) != "fedcba9876543210" {
+		t.Errorf("got: %s, %q, %v", id, key, err)
+	}
+
+	// Keys are cached and handed out as copies
+	key[0] = 'x'
+	for i := 0; i < 2; i++ {
+		if key, err := ring.Key("k1"); err != nil || string(key) != "0123456789abcdef" {
+			t.Errorf("got: %q, %v", key, err)
+		}
+	}
+	if key, _ := ring.Key("k2"); key[0] != 'f' {
+		t.Errorf("cached key was modified: %q", key)
+	}
+	if provider.calls != 2 {
+		t.Errorf("got %d provider calls, want 2", provider.calls)
+	}
+
+	// Keys outside the ring are not looked up
+	if _, err := ring.Key("k3"); !errors.Is(err, pepper.ErrUnknownKey) {
+		t.Errorf("got: %v, want: %v", err, pepper.ErrUnknownKey)
+	}
+	if _, err := ring.Key("short"); !errors.Is(err, pepper.ErrShortKey) {
+		t.Errorf("got: %v, want: %v", err, pepper.ErrShortKey)
+	}
+
+	if ring.IsRetired("k2") || !ring.IsRetired("k1") || !ring.IsRetired("k3") {
+		t.Error("wrong retired keys")
+	}
+
+	if _, _, err := (&pepper.Keyring{Provider: provider}).CurrentKey(); !errors.Is(err, pepper.ErrNoCurrent) {
+		t.Errorf("got: %v, want: %v", err, pepper.ErrNoCurrent)
+	}
+}
+
+func TestPrehash(t *testing.T) {
+	a := pepper.Prehash([]byte("0123456789abcdef"), []byte("password"))
+	b := pepper.Prehash([]byte("fedcba9876543210"), []byte("password"))
+	if len(a) != 44 || bytes.Equal(a, b) || bytes.IndexByte(a, 0) >= 0 {
+		t.Errorf("got: %s, %s", a, b)
+	}
+}
@@ -0,0 +1,247 @@
+// Package phc parses and serializes the PHC string format
+//
+//	$<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+//
+// https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md
+package phc
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxIDLength   = 32 // Maximum length of the function symbolic name
+	MaxNameLength = 32 // Maximum length of a parameter name
+)
+
+var ErrInvalid = errors.New("phc: invalid string")
+
+// B64 is the unpadded standard base64 encoding used for salt and hash values.
+var B64 = base64.RawStdEncoding.Strict()
+
+type Param struct {
+	Name  string
+	Value string
+}
+
+type Hash struct {
+	ID         string  // function symbolic name
+	Version    int     // algorithm version, only meaningful when HasVersion is set
+	HasVersion bool    // whether the string has a v=<version> segment
+	Params     []Param // parameters in order of appearance
+	Salt       string  // encoded salt, empty when absent
+	Hash       string  // encoded hash output, empty when absent
+}
+
+func invalid(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, a...))
+}
+
+func Parse(s string) (Hash, error) {
+	var h Hash
+	if !strings.HasPrefix(s, "$") {
+		return Hash{}, invalid("missing leading $")
+	}
+
+	fields := strings.Split(s[1:], "$")
+	h.ID, fields = fields[0], fields[1:]
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") && !strings.Contains(fields[0], ",") {
+		version, err := ParseUint(fields[0][2:], strconv.IntSize-1)
+		if err != nil {
+			return Hash{}, invalid("version %q", fields[0][2:])
+		}
+		h.Version, h.HasVersion = int(version), true
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && strings.Contains(fields[0], "=") {
+		for _, pair := range strings.Split(fields[0], ",") {
+			i := strings.IndexByte(pair, '=')
+			if i < 0 {
+				return Hash{}, invalid("parameter %q has no value", pair)
+			}
+			h.Params = append(h.Params, Param{Name: pair[:i], Value: pair[i+1:]})
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		h.Salt, fields = fields[0], fields[1:]
+		if h.Salt == "" {
+			return Hash{}, invalid("empty salt")
+		}
+	}
+
+	if len(fields) > 0 {
+		h.Hash, fields = fields[0], fields[1:]
+		if h.Hash == "" {
+			return Hash{}, invalid("empty hash")
+		}
+	}
+
+	if len(fields) > 0 {
+		return Hash{}, invalid("too many fields")
+	}
+
+	if err := h.Validate(); err != nil {
+		return Hash{}, err
+	}
+
+	return h, nil
+}
+
+func (h Hash) Validate() error {
+	if !isSymbol(h.ID, MaxIDLength) {
+		return invalid("id %q", h.ID)
+	}
+
+	if h.HasVersion && h.Version < 0 {
+		return invalid("version %d", h.Version)
+	}
+
+	seen := make(map[string]bool, len(h.Params))
+	for _, p := range h.Params {
+		if !isSymbol(p.Name, MaxNameLength) {
+			return invalid("parameter name %q", p.Name)
+		}
+
+		// A lone v= parameter would be read back as the version segment
+		if p.Name == "v" && !h.HasVersion && len(h.Params) == 1 {
+			return invalid("parameter name %q", p.Name)
+		}
+
+		if seen[p.Name] {
+			return invalid("duplicate parameter %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		if p.Value == "" || !isValue(p.Value) {
+			return invalid("parameter %s value %q", p.Name, p.Value)
+		}
+	}
+
+	if !isValue(h.Salt) {
+		return invalid("salt %q", h.Salt)
+	}
+
+	if !isB64(h.Hash) {
+		return invalid("hash %q", h.Hash)
+	}
+
+	if h.Hash != "" && h.Salt == "" {
+		return invalid("hash without salt")
+	}
+
+	return nil
+}
+
+func (h Hash) String() string {
+	var out strings.Builder
+	out.WriteString("$")
+	out.WriteString(h.ID)
+
+	if h.HasVersion {
+		out.WriteString("$v=")
+		out.WriteString(strconv.Itoa(h.Version))
+	}
+
+	for i, p := range h.Params {
+		if i == 0 {
+			out.WriteString("$")
+		} else {
+			out.WriteString(",")
+		}
+		out.WriteString(p.Name)
+		out.WriteString("=")
+		out.WriteString(p.Value)
+	}
+
+	if h.Salt != "" {
+		out.WriteString("$")
+		out.WriteString(h.Salt)
+
+		if h.Hash != "" {
+			out.WriteString("$")
+			out.WriteString(h.Hash)
+		}
+	}
+
+	return out.String()
+}
+
+// Param returns the value of the named parameter.
+func (h Hash) Param(name string) (string, bool) {
+	for _, p := range h.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// ParseUint parses a decimal parameter value, which must not have a sign or
+// leading zeros.
+func ParseUint(value string, bitSize int) (uint64, error) {
+	if value == "" || (len(value) > 1 && value[0] == '0') {
+		return 0, invalid("decimal %q", value)
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return 0, invalid("decimal %q", value)
+		}
+	}
+
+	n, err := strconv.ParseUint(value, 10, bitSize)
+	if err != nil {
+		return 0, invalid("decimal %q", value)
+	}
+
+	return n, nil
+}
+
+func isSymbol(s string, max int) bool {
+	if s == "" || len(s) > max {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValue(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isB64Char(c) && c != '.' && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isB64(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isB64Char(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isB64Char(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '+' || c == '/'
+}
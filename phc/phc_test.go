@@ -0,0 +1,114 @@
+package phc_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+var testParse = []struct {
+	encoded string
+	want    phc.Hash
+}{
+	{
+		encoded: "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+		want: phc.Hash{
+			ID: "argon2i", Version: 19, HasVersion: true,
+			Params: []phc.Param{{Name: "m", Value: "65536"}, {Name: "t", Value: "2"}, {Name: "p", Value: "1"}},
+			Salt:   "c29tZXNhbHQ",
+			Hash:   "wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+		},
+	},
+	{
+		encoded: "$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ",
+		want: phc.Hash{
+			ID:     "argon2i",
+			Params: []phc.Param{{Name: "m", Value: "65536"}, {Name: "t", Value: "2"}, {Name: "p", Value: "1"}},
+			Salt:   "c29tZXNhbHQ",
+			Hash:   "9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ",
+		},
+	},
+	{
+		encoded: "$argon2id$v=19$t=3,p=4,m=32,keyid=AQID,data=BAQE$c29tZXNhbHQ",
+		want: phc.Hash{
+			ID: "argon2id", Version: 19, HasVersion: true,
+			Params: []phc.Param{{Name: "t", Value: "3"}, {Name: "p", Value: "4"}, {Name: "m", Value: "32"}, {Name: "keyid", Value: "AQID"}, {Name: "data", Value: "BAQE"}},
+			Salt:   "c29tZXNhbHQ",
+		},
+	},
+	{
+		encoded: "$bcrypt-sha256$v=2,t=2b,r=12$n79VH.0Q2TMWmt3Oqt9uku",
+		want: phc.Hash{
+			ID:     "bcrypt-sha256",
+			Params: []phc.Param{{Name: "v", Value: "2"}, {Name: "t", Value: "2b"}, {Name: "r", Value: "12"}},
+			Salt:   "n79VH.0Q2TMWmt3Oqt9uku",
+		},
+	},
+	{
+		encoded: "$md5",
+		want:    phc.Hash{ID: "md5"},
+	},
+	{
+		encoded: "$scrypt$v=1",
+		want:    phc.Hash{ID: "scrypt", Version: 1, HasVersion: true},
+	},
+}
+
+func TestParse(t *testing.T) {
+	for i, v := range testParse {
+		got, err := phc.Parse(v.encoded)
+		if err != nil {
+			t.Fatalf("Test %d: failed to parse: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, v.want) {
+			t.Errorf("Test %d - got: %+v, want: %+v", i, got, v.want)
+		}
+		if s := got.String(); s != v.encoded {
+			t.Errorf("Test %d - got: %s, want: %s", i, s, v.encoded)
+		}
+	}
+}
+
+var testParseInvalid = []string{
+	"",
+	"argon2i$m=1",
+	"$",
+	"$Argon2i",
+	"$argon2_i",
+	"$aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"$argon2i$v=019$m=1",
+	"$argon2i$v=-1",
+	"$argon2i$v=x",
+	"$argon2i$m=",
+	"$argon2i$m=1,m=2",
+	"$argon2i$M=1",
+	"$argon2i$m=1!",
+	"$argon2i$m=1$",
+	"$argon2i$m=1$salt$",
+	"$argon2i$m=1$sa_lt",
+	"$argon2i$m=1$salt$ha.sh",
+	"$argon2i$m=1$salt$hash$extra",
+	"$argon2i$m=1$$hash",
+}
+
+func TestParseInvalid(t *testing.T) {
+	for i, v := range testParseInvalid {
+		if _, err := phc.Parse(v); !errors.Is(err, phc.ErrInvalid) {
+			t.Errorf("Test %d (%q) - got: %v, want: %v", i, v, err, phc.ErrInvalid)
+		}
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	for _, v := range []string{"", "01", "+1", "-1", "1a", "256"} {
+		if _, err := phc.ParseUint(v, 8); err == nil {
+			t.Errorf("%q - expected error", v)
+		}
+	}
+
+	if n, err := phc.ParseUint("255", 8); err != nil || n != 255 {
+		t.Errorf("got: %d, %v, want: 255", n, err)
+	}
+}
@@ -0,0 +1,348 @@
+// Package scrypt hashes passwords with scrypt in the PHC string format
+//
+//	$scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>
+//
+// and verifies the $7$ format of libsodium as well.
+package scrypt
+
+import (
+	"crypto/subtle"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/phc"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ID = "scrypt" // PHC id
+
+	DefaultLogN   = 15 // N = 32768, with r = 8 about 32 MiB
+	DefaultR      = 8
+	DefaultP      = 1
+	DefaultKeyLen = 32
+	MaxLogN       = 63
+)
+
+// Limits bounds the hashes a Hasher verifies, which may come from untrusted
+// storage. MaxMemory bounds 128·N·r, MaxLogN and MaxP the work on top of it,
+// each of the p passes being sequential.
+type Limits struct {
+	MaxLogN    uint8  // largest ln, log2 N
+	MaxP       uint32 // largest p
+	MaxMemory  uint64 // largest memory in KiB
+	MaxSaltLen uint32 // longest salt in bytes
+	MaxHashLen uint32 // longest hash in bytes
+	MaxPwdLen  uint32 // longest password in bytes
+}
+
+// DefaultLimits are the limits of the zero fields of Limits, N = 2^20 being
+// the most libsodium uses for sensitive data.
+var DefaultLimits = Limits{
+	MaxLogN:    20,
+	MaxP:       16,
+	MaxMemory:  1 << 21, // 2 GiB
+	MaxSaltLen: 128,
+	MaxHashLen: 128,
+	MaxPwdLen:  4096,
+}
+
+// WithDefaults returns l with its zero fields set from DefaultLimits.
+func (l Limits) WithDefaults() Limits {
+	if l.MaxLogN == 0 {
+		l.MaxLogN = DefaultLimits.MaxLogN
+	}
+	if l.MaxP == 0 {
+		l.MaxP = DefaultLimits.MaxP
+	}
+	if l.MaxMemory == 0 {
+		l.MaxMemory = DefaultLimits.MaxMemory
+	}
+	if l.MaxSaltLen == 0 {
+		l.MaxSaltLen = DefaultLimits.MaxSaltLen
+	}
+	if l.MaxHashLen == 0 {
+		l.MaxHashLen = DefaultLimits.MaxHashLen
+	}
+	if l.MaxPwdLen == 0 {
+		l.MaxPwdLen = DefaultLimits.MaxPwdLen
+	}
+
+	return l
+}
+
+type Format int
+
+const (
+	FormatPHC    Format = iota // $scrypt$ string
+	FormatSodium               // libsodium $7$ string, whose key length is always 32
+)
+
+// Params are the scrypt cost parameters.
+type Params struct {
+	LogN   uint8  // log2 of the CPU/memory cost N
+	R      uint32 // block size
+	P      uint32 // parallelization
+	KeyLen uint32 // hash length in bytes
+}
+
+// Memory returns the memory in KiB that hashing with p allocates, 128*r*(N+p)
+// bytes, or math.MaxUint64 if that overflows.
+func (p Params) Memory() uint64 {
+	hi, lo := bits.Mul64(uint64(p.R), (uint64(1)<<p.LogN)+uint64(p.P))
+	if hi != 0 || lo > math.MaxUint64/128 {
+		return math.MaxUint64
+	}
+
+	return lo * 128 / 1024
+}
+
+// ValidateInputs checks params the way agron2.ValidateInputs checks an
+// Argon2 context, N for the time, r for the memory and p for the threads.
+func ValidateInputs(params Params, salt []byte) error {
+	switch {
+	case len(salt) < int(agron2.Argon2MinSaltLength):
+		return kdf.NewError(kdf.SaltTooShort, "salt")
+	case params.KeyLen == 0:
+		return kdf.NewError(kdf.SecretPtrMismatch, "KeyLen")
+	case params.R < 1:
+		return kdf.NewError(kdf.MemoryTooLittle, "r")
+	case params.P < 1:
+		return kdf.NewError(kdf.ThreadsTooFew, "p")
+	case uint64(params.R)*uint64(params.P) >= 1<<30:
+		return kdf.NewError(kdf.ThreadsTooMany, "p")
+	case params.LogN < 1:
+		return kdf.NewError(kdf.TimeTooSmall, "ln")
+	case params.LogN > MaxLogN || uint64(params.LogN) >= 16*uint64(params.R):
+		return kdf.NewError(kdf.TimeTooLarge, "ln")
+	case params.Memory() > agron2.Argon2MaxMemory:
+		return kdf.NewError(kdf.MemoryTooMuch, "ln")
+	}
+
+	return nil
+}
+
+// Hasher hashes passwords with scrypt. Its methods make it a crypt.Hasher.
+type Hasher struct {
+	Params  Params // zero fields take the defaults
+	SaltLen uint32 // salt length in bytes, kdf.DefaultSaltLength if zero
+	Format  Format
+	Limits  Limits // limits of verified hashes
+}
+
+func (h Hasher) params() Params {
+	p := h.Params
+	if p.LogN == 0 {
+		p.LogN = DefaultLogN
+	}
+	if p.R == 0 {
+		p.R = DefaultR
+	}
+	if p.P == 0 {
+		p.P = DefaultP
+	}
+	if p.KeyLen == 0 || h.Format == FormatSodium {
+		p.KeyLen = DefaultKeyLen
+	}
+
+	return p
+}
+
+// ID returns the PHC id, or "7" for the libsodium format.
+func (h Hasher) ID() string {
+	if h.Format == FormatSodium {
+		return "7"
+	}
+
+	return ID
+}
+
+// Hash hashes pwd with a random salt.
+func (h Hasher) Hash(pwd string) (string, error) {
+	params := h.params()
+	saltLen := h.SaltLen
+	if saltLen == 0 {
+		saltLen = kdf.DefaultSaltLength
+	}
+
+	raw, err := kdf.GenerateSalt(nil, saltLen)
+	if err != nil {
+		return "", err
+	}
+	salt := []byte(raw)
+	if h.Format == FormatSodium {
+		// The salt of the $7$ format is text, used as is
+		salt = []byte(encode64(salt))
+	}
+
+	hash, err := key(pwd, salt, params)
+	if err != nil {
+		return "", err
+	}
+
+	if h.Format == FormatSodium {
+		return encodeSodium(params, salt, hash), nil
+	}
+
+	return encodePHC(params, salt, hash), nil
+}
+
+func key(pwd string, salt []byte, params Params) ([]byte, error) {
+	if err := ValidateInputs(params, salt); err != nil {
+		return nil, err
+	}
+
+	return scrypt.Key([]byte(pwd), salt, 1<<params.LogN, int(params.R), int(params.P), int(params.KeyLen))
+}
+
+func encodePHC(params Params, salt, hash []byte) string {
+	h := phc.Hash{
+		ID: ID,
+		Params: []phc.Param{
+			{Name: "ln", Value: strconv.FormatUint(uint64(params.LogN), 10)},
+			{Name: "r", Value: strconv.FormatUint(uint64(params.R), 10)},
+			{Name: "p", Value: strconv.FormatUint(uint64(params.P), 10)},
+		},
+		Salt: phc.B64.EncodeToString(salt),
+		Hash: phc.B64.EncodeToString(hash),
+	}
+
+	return h.String()
+}
+
+func decodePHC(encoded string) (Params, []byte, []byte, error) {
+	h, err := phc.Parse(encoded)
+	if err != nil {
+		return Params{}, nil, nil, kdf.DecodingError("", err)
+	}
+	if h.ID != ID || h.HasVersion {
+		return Params{}, nil, nil, kdf.NewError(kdf.IncorrectType, "id")
+	}
+
+	var params Params
+	for _, name := range []string{"ln", "r", "p"} {
+		value, ok := h.Param(name)
+		if !ok {
+			return Params{}, nil, nil, kdf.DecodingError(name, nil)
+		}
+
+		bitSize := 32
+		if name == "ln" {
+			bitSize = 8
+		}
+		n, err := phc.ParseUint(value, bitSize)
+		if err != nil {
+			return Params{}, nil, nil, kdf.DecodingError(name, err)
+		}
+
+		switch name {
+		case "ln":
+			params.LogN = uint8(n)
+		case "r":
+			params.R = uint32(n)
+		case "p":
+			params.P = uint32(n)
+		}
+	}
+	if len(h.Params) != 3 {
+		return Params{}, nil, nil, kdf.DecodingError("", nil)
+	}
+
+	salt, err := phc.B64.DecodeString(h.Salt)
+	if err != nil || h.Salt == "" {
+		return Params{}, nil, nil, kdf.DecodingError("salt", err)
+	}
+	hash, err := phc.B64.DecodeString(h.Hash)
+	if err != nil || h.Hash == "" {
+		return Params{}, nil, nil, kdf.DecodingError("hash", err)
+	}
+	params.KeyLen = uint32(len(hash))
+
+	return params, salt, hash, nil
+}
+
+// decode reads a $scrypt$ or $7$ string.
+func decode(encoded string) (Params, []byte, []byte, Format, error) {
+	if strings.HasPrefix(encoded, SodiumPrefix) {
+		params, salt, hash, err := decodeSodium(encoded)
+		return params, salt, hash, FormatSodium, err
+	}
+
+	params, salt, hash, err := decodePHC(encoded)
+	return params, salt, hash, FormatPHC, err
+}
+
+// checkLimits enforces limits on a decoded hash before computing it.
+func checkLimits(limits Limits, params Params, salt, hash []byte, pwdLen int) error {
+	limits = limits.WithDefaults()
+
+	switch {
+	case int64(pwdLen) > int64(limits.MaxPwdLen):
+		return kdf.NewError(kdf.PwdTooLong, "Pwd")
+	case params.LogN > limits.MaxLogN:
+		return kdf.NewError(kdf.TimeTooLarge, "ln")
+	case params.LogN <= MaxLogN && params.Memory() > limits.MaxMemory:
+		return kdf.NewError(kdf.MemoryTooMuch, "ln")
+	case params.P > limits.MaxP:
+		return kdf.NewError(kdf.ThreadsTooMany, "p")
+	case uint64(len(salt)) > uint64(limits.MaxSaltLen):
+		return kdf.NewError(kdf.SaltTooLong, "salt")
+	case uint64(len(hash)) > uint64(limits.MaxHashLen):
+		return kdf.NewError(kdf.SecretTooLong, "hash")
+	}
+
+	return nil
+}
+
+// Verify returns nil if pwd matches encoded, a $scrypt$ or $7$ string,
+// after checking encoded against h.Limits.
+func (h Hasher) Verify(encoded, pwd string) error {
+	params, salt, hash, _, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+	if err = checkLimits(h.Limits, params, salt, hash, len(pwd)); err != nil {
+		return err
+	}
+
+	sum, err := key(pwd, salt, params)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(sum, hash) == 1 {
+		return nil
+	}
+
+	return kdf.NewError(kdf.VerifyMismatch, "")
+}
+
+// NeedsRehash reports whether encoded has a lower N, r or p than new hashes,
+// or another key length, salt length or format. Costlier hashes are kept, so
+// that upgrading never weakens them.
+func (h Hasher) NeedsRehash(encoded string) (bool, error) {
+	params, salt, _, format, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	saltLen := h.SaltLen
+	if saltLen == 0 {
+		saltLen = kdf.DefaultSaltLength
+	}
+	if format == FormatSodium {
+		// Salts of $7$ strings are encoded
+		saltLen = uint32(len(encode64(make([]byte, saltLen))))
+	}
+
+	want := h.params()
+	return params.LogN < want.LogN ||
+		params.R < want.R ||
+		params.P < want.P ||
+		params.KeyLen != want.KeyLen ||
+		format != h.Format ||
+		uint32(len(salt)) != saltLen, nil
+}
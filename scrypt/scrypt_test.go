@@ -0,0 +1,175 @@
+package scrypt_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/phc"
+	"github.com/fikryfahrezy/crypt/scrypt"
+)
+
+// Test vector of libsodium and RFC 7914, section 12
+const (
+	sodiumVector = "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D"
+	rfcVector    = "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887"
+)
+
+var testHasher = scrypt.Hasher{Params: scrypt.Params{LogN: 4, R: 8, P: 1}}
+
+func TestVectors(t *testing.T) {
+	hash, _ := hex.DecodeString(rfcVector)
+	phcVector := "$scrypt$ln=14,r=8,p=1$" + phc.B64.EncodeToString([]byte("SodiumChloride")) + "$" + phc.B64.EncodeToString(hash)
+
+	h := scrypt.Hasher{}
+	for i, encoded := range []string{sodiumVector, phcVector} {
+		if err := h.Verify(encoded, "pleaseletmein"); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+		if err := h.Verify(encoded, "pleaseletmeout"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	for _, format := range []scrypt.Format{scrypt.FormatPHC, scrypt.FormatSodium} {
+		h := testHasher
+		h.Format = format
+		encoded, err := h.Hash("password")
+		if err != nil {
+			t.Fatalf("failed to hash: %v", err)
+		}
+
+		prefix := "$scrypt$ln=4,r=8,p=1$"
+		if format == scrypt.FormatSodium {
+			prefix = "$7$2" + "6...." + "/...."
+		}
+		if !strings.HasPrefix(encoded, prefix) {
+			t.Errorf("Format %d - got: %s, want prefix: %s", format, encoded, prefix)
+		}
+
+		if err := h.Verify(encoded, "password"); err != nil {
+			t.Errorf("Format %d - got: %v, want: nil", format, err)
+		}
+		if err := h.Verify(encoded, "wrong"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Format %d - got: %v, want: %v", format, err, agron2.ErrMismatch)
+		}
+
+		needsRehash, err := h.NeedsRehash(encoded)
+		if err != nil || needsRehash {
+			t.Errorf("Format %d - got: %v, %v, want: false, nil", format, needsRehash, err)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	encoded, err := testHasher.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	outdated := []scrypt.Hasher{testHasher, testHasher, testHasher, testHasher, testHasher}
+	outdated[0].Params.LogN = 5
+	outdated[1].Params.R = 16
+	outdated[2].Params.P = 2
+	outdated[3].SaltLen = 32
+	outdated[4].Format = scrypt.FormatSodium
+	for i, h := range outdated {
+		needsRehash, err := h.NeedsRehash(encoded)
+		if err != nil || !needsRehash {
+			t.Errorf("Hasher %d - got: %v, %v, want: true, nil", i, needsRehash, err)
+		}
+	}
+
+	// Costlier hashes than the policy are kept
+	weaker := []scrypt.Hasher{testHasher, testHasher}
+	weaker[0].Params.LogN = 3
+	weaker[1].Params.R = 4
+	for i, h := range weaker {
+		needsRehash, err := h.NeedsRehash(encoded)
+		if err != nil || needsRehash {
+			t.Errorf("Hasher %d - got: %v, %v, want: false, nil", i, needsRehash, err)
+		}
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	salt := []byte("somesalt")
+	params := scrypt.Params{LogN: 4, R: 8, P: 1, KeyLen: 32}
+
+	tests := []struct {
+		params scrypt.Params
+		salt   []byte
+		want   error
+	}{
+		{params, salt, nil},
+		{params, []byte("short"), agron2.ErrSaltTooShort},
+		{scrypt.Params{LogN: 0, R: 8, P: 1, KeyLen: 32}, salt, agron2.ErrTimeTooSmall},
+		{scrypt.Params{LogN: 64, R: 8, P: 1, KeyLen: 32}, salt, agron2.ErrTimeTooLarge},
+		{scrypt.Params{LogN: 16, R: 1, P: 1, KeyLen: 32}, salt, agron2.ErrTimeTooLarge},
+		{scrypt.Params{LogN: 4, R: 0, P: 1, KeyLen: 32}, salt, agron2.ErrMemoryTooLittle},
+		{scrypt.Params{LogN: 4, R: 8, P: 0, KeyLen: 32}, salt, agron2.ErrThreadsTooFew},
+		{scrypt.Params{LogN: 4, R: 1 << 15, P: 1 << 15, KeyLen: 32}, salt, agron2.ErrThreadsTooMany},
+		{scrypt.Params{LogN: 40, R: 8, P: 1, KeyLen: 32}, salt, agron2.ErrMemoryTooMuch},
+		{scrypt.Params{LogN: 4, R: 8, P: 1}, salt, agron2.ErrSecretPtrMismatch},
+	}
+	for i, test := range tests {
+		if err := scrypt.ValidateInputs(test.params, test.salt); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+}
+
+func TestVerifyLimits(t *testing.T) {
+	h := scrypt.Hasher{Limits: scrypt.Limits{MaxMemory: 1024, MaxP: 2, MaxPwdLen: 16}}
+
+	tests := []struct {
+		encoded string
+		pwd     string
+		want    error
+	}{
+		// N = 2^14 with r = 8 takes 16 MiB
+		{sodiumVector, "pleaseletmein", agron2.ErrMemoryTooMuch},
+		{"$scrypt$ln=40,r=8,p=1$c29tZXNhbHQ$aGFzaA", "password", agron2.ErrTimeTooLarge},
+		{"$scrypt$ln=4,r=8,p=3$c29tZXNhbHQ$aGFzaA", "password", agron2.ErrThreadsTooMany},
+		{"$scrypt$ln=4,r=8,p=1$c29tZXNhbHQ$aGFzaA", strings.Repeat("x", 17), agron2.ErrPwdTooLong},
+		{"$scrypt$ln=4,r=8,p=1$c29tZXNhbHQ$aGFzaA", "password", agron2.ErrMismatch},
+	}
+	for i, test := range tests {
+		if err := h.Verify(test.encoded, test.pwd); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+
+	// The default limits bound ln and p whatever the memory
+	if err := (scrypt.Hasher{}).Verify("$scrypt$ln=21,r=1,p=1$c29tZXNhbHQ$aGFzaA", "password"); !errors.Is(err, agron2.ErrTimeTooLarge) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrTimeTooLarge)
+	}
+	if err := (scrypt.Hasher{}).Verify("$scrypt$ln=4,r=1,p=17$c29tZXNhbHQ$aGFzaA", "password"); !errors.Is(err, agron2.ErrThreadsTooMany) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrThreadsTooMany)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    error
+	}{
+		{"$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$aGFzaA", agron2.ErrIncorrectType},
+		{"$scrypt$ln=4,r=8$c29tZXNhbHQ$aGFzaA", agron2.ErrDecoding},
+		{"$scrypt$ln=x,r=8,p=1$c29tZXNhbHQ$aGFzaA", agron2.ErrDecoding},
+		{"$scrypt$ln=4,r=8,p=1$c29tZXNhbHQ", agron2.ErrDecoding},
+		{"$scrypt$ln=4,r=8,p=1$c29tZXNhbHQ$!!", agron2.ErrDecoding},
+		{"$7$C6....", agron2.ErrDecoding},
+		{"$7$C6..../....SodiumChloride$short", agron2.ErrDecoding},
+		{"$7$C6..!./....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D", agron2.ErrDecoding},
+	}
+	for i, test := range tests {
+		if err := testHasher.Verify(test.encoded, "password"); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+}
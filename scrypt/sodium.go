@@ -0,0 +1,144 @@
+package scrypt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+// SodiumPrefix starts the crypt format of libsodium and Colin Percival's
+// scrypt-kdf
+//
+//	$7$<N><r><p><salt>$<hash>
+//
+// where N is log2 N in one character, r and p are 30-bit numbers in five
+// characters, the salt is used as is and the hash is 32 bytes long.
+const SodiumPrefix = "$7$"
+
+const (
+	itoa64        = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	sodiumKeyLen  = 32
+	sodiumHashLen = 43 // encoded length of sodiumKeyLen
+)
+
+func atoi64(c byte) (uint32, bool) {
+	i := strings.IndexByte(itoa64, c)
+	return uint32(i), i >= 0
+}
+
+// encode64Uint32 encodes the bits low bits of src, least significant first.
+func encode64Uint32(dst *strings.Builder, src, bits uint32) {
+	for bit := uint32(0); bit < bits; bit += 6 {
+		dst.WriteByte(itoa64[src&0x3f])
+		src >>= 6
+	}
+}
+
+func decode64Uint32(src string) (uint32, bool) {
+	var ret uint32
+	for i := 0; i < len(src); i++ {
+		c, ok := atoi64(src[i])
+		if !ok {
+			return 0, false
+		}
+		ret |= c << (6 * uint(i))
+	}
+
+	return ret, true
+}
+
+// encode64 encodes src in groups of three little-endian bytes.
+func encode64(src []byte) string {
+	var dst strings.Builder
+	for i := 0; i < len(src); {
+		var value, bits uint32
+		for bits < 24 && i < len(src) {
+			value |= uint32(src[i]) << bits
+			bits += 8
+			i++
+		}
+		encode64Uint32(&dst, value, bits)
+	}
+
+	return dst.String()
+}
+
+func decode64(src string) ([]byte, bool) {
+	var dst []byte
+	for len(src) > 0 {
+		n := 4
+		if len(src) < n {
+			n = len(src)
+		}
+		if n == 1 {
+			return nil, false
+		}
+
+		value, ok := decode64Uint32(src[:n])
+		if !ok {
+			return nil, false
+		}
+		for bits := 6 * n; bits >= 8; bits -= 8 {
+			dst = append(dst, byte(value))
+			value >>= 8
+		}
+		src = src[n:]
+	}
+
+	return dst, true
+}
+
+func invalid(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: %s", phc.ErrInvalid, fmt.Sprintf(format, a...))
+}
+
+// decodeSodium reads a $7$ string.
+func decodeSodium(encoded string) (Params, []byte, []byte, error) {
+	s := strings.TrimPrefix(encoded, SodiumPrefix)
+	i := strings.IndexByte(s, '$')
+	if i < 11 {
+		return Params{}, nil, nil, kdf.DecodingError("", invalid("not a $7$ string"))
+	}
+	setting, b64Hash := s[:i], s[i+1:]
+
+	logN, ok := atoi64(setting[0])
+	if !ok {
+		return Params{}, nil, nil, kdf.DecodingError("ln", invalid("N %q", setting[0]))
+	}
+	r, ok := decode64Uint32(setting[1:6])
+	if !ok {
+		return Params{}, nil, nil, kdf.DecodingError("r", invalid("r %q", setting[1:6]))
+	}
+	p, ok := decode64Uint32(setting[6:11])
+	if !ok {
+		return Params{}, nil, nil, kdf.DecodingError("p", invalid("p %q", setting[6:11]))
+	}
+
+	salt := setting[11:]
+	if strings.ContainsAny(salt, "$\x00") {
+		return Params{}, nil, nil, kdf.DecodingError("salt", invalid("salt %q", salt))
+	}
+
+	hash, ok := decode64(b64Hash)
+	if !ok || len(b64Hash) != sodiumHashLen {
+		return Params{}, nil, nil, kdf.DecodingError("hash", invalid("hash %q", b64Hash))
+	}
+
+	params := Params{LogN: uint8(logN), R: r, P: p, KeyLen: sodiumKeyLen}
+	return params, []byte(salt), hash, nil
+}
+
+func encodeSodium(params Params, salt, hash []byte) string {
+	var out strings.Builder
+	out.WriteString(SodiumPrefix)
+	out.WriteByte(itoa64[params.LogN])
+	encode64Uint32(&out, params.R, 30)
+	encode64Uint32(&out, params.P, 30)
+	out.Write(salt)
+	out.WriteByte('$')
+	out.WriteString(encode64(hash))
+
+	return out.String()
+}
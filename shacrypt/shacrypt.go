@@ -0,0 +1,354 @@
+// Package shacrypt hashes passwords with the SHA-256-crypt and SHA-512-crypt
+// schemes of glibc, found in /etc/shadow and LDAP directories
+//
+//	$5$rounds=<rounds>$<salt>$<hash>
+//	$6$rounds=<rounds>$<salt>$<hash>
+//
+// as specified by Ulrich Drepper in "Unix crypt using SHA-256 and SHA-512".
+// The rounds= field is optional, salts are at most 16 characters long.
+package shacrypt
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/kdf"
+	"github.com/fikryfahrezy/crypt/phc"
+)
+
+const (
+	ID256 = "5" // SHA-256-crypt
+	ID512 = "6" // SHA-512-crypt
+
+	DefaultRounds    = 5000
+	MinRounds        = 1000
+	MaxRounds        = 999999999
+	DefaultMaxRounds = 10000000 // most rounds of verified hashes
+	MaxSaltLength    = 16
+)
+
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const roundsPrefix = "rounds="
+
+type scheme struct {
+	new     func() hash.Hash
+	hashLen int      // encoded length
+	order   [][3]int // bytes of the digest in groups of 24 bits
+}
+
+var schemes = map[string]scheme{
+	ID256: {sha256.New, 43, [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+		{-1, 31, 30},
+	}},
+	ID512: {sha512.New, 86, [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41}, {-1, -1, 63},
+	}},
+}
+
+func invalid(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: %s", phc.ErrInvalid, fmt.Sprintf(format, a...))
+}
+
+// Setting is the part of a hash before the hash itself.
+type Setting struct {
+	ID     string // ID256 or ID512
+	Rounds uint32 // DefaultRounds when not Custom
+	Custom bool   // whether the string has a rounds= field
+	Salt   string
+}
+
+// ParseSetting reads the setting at the start of s, a hash or a setting the
+// way crypt(3) of glibc does: rounds are clamped to [MinRounds, MaxRounds],
+// the salt ends at the next $ and is truncated to MaxSaltLength characters.
+// It returns the rest of s after the salt.
+func ParseSetting(s string) (Setting, string, error) {
+	var setting Setting
+	for id := range schemes {
+		if strings.HasPrefix(s, "$"+id+"$") {
+			setting.ID = id
+		}
+	}
+	if setting.ID == "" {
+		return Setting{}, "", kdf.NewError(kdf.IncorrectType, "id")
+	}
+	s = s[len(setting.ID)+2:]
+
+	setting.Rounds = DefaultRounds
+	if strings.HasPrefix(s, roundsPrefix) {
+		value := s[len(roundsPrefix):]
+		// Like strtoul, glibc keeps the field in the salt when it does not
+		// end with $
+		if i := strings.IndexByte(value, '$'); i >= 0 {
+			rounds, err := strconv.ParseUint(value[:i], 10, 64)
+			if err == nil || isRange(err) {
+				switch {
+				case err != nil || rounds > MaxRounds:
+					rounds = MaxRounds
+				case rounds < MinRounds:
+					rounds = MinRounds
+				}
+				setting.Rounds, setting.Custom = uint32(rounds), true
+				s = value[i+1:]
+			}
+		}
+	}
+
+	end := strings.IndexByte(s, '$')
+	if end < 0 {
+		end = len(s)
+	}
+	salt := s[:end]
+	if len(salt) > MaxSaltLength {
+		salt = salt[:MaxSaltLength]
+	}
+	setting.Salt = salt
+
+	return setting, s[len(salt):], nil
+}
+
+func isRange(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+// String returns the setting as it starts a hash.
+func (s Setting) String() string {
+	var out strings.Builder
+	out.WriteString("$" + s.ID + "$")
+	if s.Custom {
+		out.WriteString(roundsPrefix + strconv.FormatUint(uint64(s.Rounds), 10) + "$")
+	}
+	out.WriteString(s.Salt)
+
+	return out.String()
+}
+
+// Crypt returns the hash of key with setting as crypt(3) of glibc does.
+func Crypt(key []byte, setting Setting) string {
+	sc := schemes[setting.ID]
+	salt := []byte(setting.Salt)
+
+	// Digest B of key, salt, key
+	h := sc.new()
+	h.Write(key)
+	h.Write(salt)
+	h.Write(key)
+	b := h.Sum(nil)
+
+	// Digest A of key, salt, B for each byte of key, then B or key for each
+	// bit of the key length
+	h.Reset()
+	h.Write(key)
+	h.Write(salt)
+	n := len(key)
+	for ; n > len(b); n -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:n])
+	for n = len(key); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(key)
+		}
+	}
+	a := h.Sum(nil)
+
+	// Sequence P of the digest of key once per byte of key
+	h.Reset()
+	for i := 0; i < len(key); i++ {
+		h.Write(key)
+	}
+	p := produce(h.Sum(nil), len(key))
+
+	// Sequence S of the digest of salt 16 + A[0] times
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+	s := produce(h.Sum(nil), len(salt))
+
+	c := a
+	for i := uint32(0); i < setting.Rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString(setting.String())
+	out.WriteByte('$')
+	for _, group := range sc.order {
+		var w uint32
+		chars := 4
+		for _, i := range group {
+			w <<= 8
+			if i < 0 {
+				chars--
+				continue
+			}
+			w |= uint32(c[i])
+		}
+		for ; chars > 0; chars-- {
+			out.WriteByte(itoa64[w&0x3f])
+			w >>= 6
+		}
+	}
+
+	return out.String()
+}
+
+// produce repeats digest to length n.
+func produce(digest []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for ; n > len(digest); n -= len(digest) {
+		out = append(out, digest...)
+	}
+
+	return append(out, digest[:n]...)
+}
+
+// decode reads a hash, which must be as Crypt writes it.
+func decode(encoded string) (Setting, error) {
+	setting, rest, err := ParseSetting(encoded)
+	if err != nil {
+		return Setting{}, err
+	}
+
+	if setting.Salt == "" || !strings.HasPrefix(rest, "$") {
+		return Setting{}, kdf.DecodingError("salt", invalid("salt of %q", encoded))
+	}
+	hash := rest[1:]
+	if len(hash) != schemes[setting.ID].hashLen || strings.Trim(hash, itoa64) != "" {
+		return Setting{}, kdf.DecodingError("hash", invalid("hash %q", hash))
+	}
+	if setting.String()+rest != encoded {
+		return Setting{}, kdf.DecodingError("rounds", invalid("rounds of %q", encoded))
+	}
+
+	return setting, nil
+}
+
+// Hasher hashes passwords with SHA-crypt. Its methods make it a crypt.Hasher
+// that verifies hashes of both schemes.
+type Hasher struct {
+	Scheme string // ID256 or ID512, ID512 if empty
+	Rounds uint32 // DefaultRounds if zero, clamped like the rounds= field
+
+	// MaxRounds makes hashes with more rounds fail verification with
+	// kdf.ErrTimeTooLarge, DefaultMaxRounds if zero.
+	MaxRounds uint32
+
+	Limits agron2.DecodeLimits // password length of verified hashes
+}
+
+func (h Hasher) setting() Setting {
+	s := Setting{ID: h.Scheme, Rounds: h.Rounds, Custom: h.Rounds != 0 && h.Rounds != DefaultRounds}
+	if s.ID == "" {
+		s.ID = ID512
+	}
+	switch {
+	case s.Rounds == 0:
+		s.Rounds = DefaultRounds
+	case s.Rounds < MinRounds:
+		s.Rounds = MinRounds
+	case s.Rounds > MaxRounds:
+		s.Rounds = MaxRounds
+	}
+
+	return s
+}
+
+// ID returns ID256 or ID512.
+func (h Hasher) ID() string {
+	return h.setting().ID
+}
+
+// Hash hashes pwd with a random salt of MaxSaltLength characters.
+func (h Hasher) Hash(pwd string) (string, error) {
+	setting := h.setting()
+	if _, ok := schemes[setting.ID]; !ok {
+		return "", kdf.NewError(kdf.IncorrectType, "Scheme")
+	}
+
+	raw, err := kdf.GenerateSalt(nil, MaxSaltLength)
+	if err != nil {
+		return "", err
+	}
+	salt := []byte(raw)
+	for i, c := range salt {
+		salt[i] = itoa64[c&0x3f]
+	}
+	setting.Salt = string(salt)
+
+	return Crypt([]byte(pwd), setting), nil
+}
+
+// Verify returns nil if pwd matches encoded, a $5$ or $6$ hash, after
+// checking its rounds against h.MaxRounds and pwd against h.Limits.
+func (h Hasher) Verify(encoded, pwd string) error {
+	setting, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+
+	maxRounds := h.MaxRounds
+	if maxRounds == 0 {
+		maxRounds = DefaultMaxRounds
+	}
+	switch {
+	case int64(len(pwd)) > int64(h.Limits.WithDefaults().MaxPwdLen):
+		return kdf.NewError(kdf.PwdTooLong, "Pwd")
+	case setting.Rounds > maxRounds:
+		return kdf.NewError(kdf.TimeTooLarge, "rounds")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(Crypt([]byte(pwd), setting)), []byte(encoded)) == 1 {
+		return nil
+	}
+
+	return kdf.NewError(kdf.VerifyMismatch, "")
+}
+
+// NeedsRehash reports whether encoded has another scheme or fewer rounds than
+// new hashes, or a salt shorter than MaxSaltLength. Hashes with more rounds
+// are kept, so that upgrading never weakens them.
+func (h Hasher) NeedsRehash(encoded string) (bool, error) {
+	setting, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	want := h.setting()
+	return setting.ID != want.ID ||
+		setting.Rounds < want.Rounds ||
+		len(setting.Salt) != MaxSaltLength, nil
+}
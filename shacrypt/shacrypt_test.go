@@ -0,0 +1,167 @@
+package shacrypt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fikryfahrezy/crypt/agron2"
+	"github.com/fikryfahrezy/crypt/shacrypt"
+)
+
+// Test vectors of "Unix crypt using SHA-256 and SHA-512"
+var testVectors = []struct {
+	setting  string
+	password string
+	encoded  string
+}{
+	{"$5$saltstring", "Hello world!", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+	{"$5$rounds=10000$saltstringsaltstring", "Hello world!", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+	{"$5$rounds=5000$toolongsaltstring", "This is just a test", "$5$rounds=5000$toolongsaltstrin$Un/5jzAHMgOGZ5.mWJpuVolil07guHPvOW8mGRcvxa5"},
+	{"$5$rounds=1400$anotherlongsaltstring", "a very much longer text to encrypt.  This one even stretches over morethan one line.", "$5$rounds=1400$anotherlongsalts$Rx.j8H.h8HjEDGomFU8bDkXm3XIUnzyxf12oP84Bnq1"},
+	{"$5$rounds=77777$short", "we have a short salt string but not a short password", "$5$rounds=77777$short$JiO1O3ZpDAxGJeaDIuqCoEFysAe1mZNJRs3pw0KQRd/"},
+	{"$5$rounds=123456$asaltof16chars..", "a short string", "$5$rounds=123456$asaltof16chars..$gP3VQ/6X7UUEW3HkBn2w1/Ptq2jxPyzV/cZKmF/wJvD"},
+	{"$5$rounds=10$roundstoolow", "the minimum number is still observed", "$5$rounds=1000$roundstoolow$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC"},
+	{"$6$saltstring", "Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+	{"$6$rounds=10000$saltstringsaltstring", "Hello world!", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+	{"$6$rounds=5000$toolongsaltstring", "This is just a test", "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+	{"$6$rounds=1400$anotherlongsaltstring", "a very much longer text to encrypt.  This one even stretches over morethan one line.", "$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1"},
+	{"$6$rounds=77777$short", "we have a short salt string but not a short password", "$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0"},
+	{"$6$rounds=123456$asaltof16chars..", "a short string", "$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1"},
+	{"$6$rounds=10$roundstoolow", "the minimum number is still observed", "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+}
+
+func TestCrypt(t *testing.T) {
+	for i, v := range testVectors {
+		setting, _, err := shacrypt.ParseSetting(v.setting)
+		if err != nil {
+			t.Fatalf("Test %d - failed to parse: %v", i, err)
+		}
+		if got := shacrypt.Crypt([]byte(v.password), setting); got != v.encoded {
+			t.Errorf("Test %d - got: %s, want: %s", i, got, v.encoded)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	h := shacrypt.Hasher{}
+	for i, v := range testVectors {
+		if err := h.Verify(v.encoded, v.password); err != nil {
+			t.Errorf("Test %d - error: %v", i, err)
+		}
+		if err := h.Verify(v.encoded, v.password+"x"); !errors.Is(err, agron2.ErrMismatch) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, agron2.ErrMismatch)
+		}
+	}
+}
+
+func TestParseSetting(t *testing.T) {
+	tests := []struct {
+		s    string
+		want shacrypt.Setting
+		rest string
+	}{
+		{"$5$salt", shacrypt.Setting{ID: "5", Rounds: 5000, Salt: "salt"}, ""},
+		{"$6$salt$hash", shacrypt.Setting{ID: "6", Rounds: 5000, Salt: "salt"}, "$hash"},
+		{"$6$rounds=5000$salt", shacrypt.Setting{ID: "6", Rounds: 5000, Custom: true, Salt: "salt"}, ""},
+		{"$6$rounds=99999999999$salt", shacrypt.Setting{ID: "6", Rounds: shacrypt.MaxRounds, Custom: true, Salt: "salt"}, ""},
+		{"$6$rounds=x$salt", shacrypt.Setting{ID: "6", Rounds: 5000, Salt: "rounds=x"}, "$salt"},
+		{"$6$rounds=1000", shacrypt.Setting{ID: "6", Rounds: 5000, Salt: "rounds=1000"}, ""},
+		{"$6$0123456789abcdefgh$hash", shacrypt.Setting{ID: "6", Rounds: 5000, Salt: "0123456789abcdef"}, "gh$hash"},
+	}
+	for i, test := range tests {
+		got, rest, err := shacrypt.ParseSetting(test.s)
+		if err != nil || got != test.want || rest != test.rest {
+			t.Errorf("Test %d - got: %+v, %q, %v, want: %+v, %q", i, got, rest, err, test.want, test.rest)
+		}
+	}
+
+	if _, _, err := shacrypt.ParseSetting("$1$salt"); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		hasher shacrypt.Hasher
+		prefix string
+	}{
+		{shacrypt.Hasher{}, "$6$"},
+		{shacrypt.Hasher{Scheme: shacrypt.ID256, Rounds: 5000}, "$5$"},
+		{shacrypt.Hasher{Rounds: 1200}, "$6$rounds=1200$"},
+		{shacrypt.Hasher{Rounds: 10}, "$6$rounds=1000$"},
+	}
+	for i, test := range tests {
+		encoded, err := test.hasher.Hash("password")
+		if err != nil {
+			t.Fatalf("failed to hash: %v", err)
+		}
+		if !strings.HasPrefix(encoded, test.prefix) || strings.HasPrefix(encoded, test.prefix+"rounds=") {
+			t.Errorf("Test %d - got: %s, want prefix: %s", i, encoded, test.prefix)
+		}
+
+		if err := test.hasher.Verify(encoded, "password"); err != nil {
+			t.Errorf("Test %d - got: %v, want: nil", i, err)
+		}
+		needsRehash, err := test.hasher.NeedsRehash(encoded)
+		if err != nil || needsRehash {
+			t.Errorf("Test %d - got: %v, %v, want: false, nil", i, needsRehash, err)
+		}
+	}
+
+	if _, err := (shacrypt.Hasher{Scheme: "1"}).Hash("password"); !errors.Is(err, agron2.ErrIncorrectType) {
+		t.Errorf("got: %v, want: %v", err, agron2.ErrIncorrectType)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	encoded, err := shacrypt.Hasher{}.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	for i, h := range []shacrypt.Hasher{{Scheme: shacrypt.ID256}, {Rounds: 10000}} {
+		needsRehash, err := h.NeedsRehash(encoded)
+		if err != nil || !needsRehash {
+			t.Errorf("Hasher %d - got: %v, %v, want: true, nil", i, needsRehash, err)
+		}
+	}
+
+	// More rounds than the policy are kept
+	costly, err := shacrypt.Hasher{Rounds: 10000}.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if needsRehash, err := (shacrypt.Hasher{}).NeedsRehash(costly); err != nil || needsRehash {
+		t.Errorf("got: %v, %v, want: false, nil", needsRehash, err)
+	}
+
+	// The salt of 10 characters is too short
+	needsRehash, err := shacrypt.Hasher{}.NeedsRehash(testVectors[7].encoded)
+	if err != nil || !needsRehash {
+		t.Errorf("got: %v, %v, want: true, nil", needsRehash, err)
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	tests := []struct {
+		hasher  shacrypt.Hasher
+		encoded string
+		want    error
+	}{
+		{shacrypt.Hasher{MaxRounds: 100000}, testVectors[5].encoded, agron2.ErrTimeTooLarge},
+		{shacrypt.Hasher{Limits: agron2.DecodeLimits{MaxPwdLen: 4}}, testVectors[5].encoded, agron2.ErrPwdTooLong},
+		{shacrypt.Hasher{}, "$1$saltstring$hash", agron2.ErrIncorrectType},
+		{shacrypt.Hasher{}, "$5$saltstring", agron2.ErrDecoding},
+		{shacrypt.Hasher{}, "$5$$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", agron2.ErrDecoding},
+		{shacrypt.Hasher{}, "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc", agron2.ErrDecoding},
+		{shacrypt.Hasher{}, "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWE!5", agron2.ErrDecoding},
+		{shacrypt.Hasher{}, "$5$rounds=10$roundstoolow$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC", agron2.ErrDecoding},
+		{shacrypt.Hasher{}, "$5$toolongsaltstring$Un/5jzAHMgOGZ5.mWJpuVolil07guHPvOW8mGRcvxa5", agron2.ErrDecoding},
+	}
+	for i, test := range tests {
+		if err := test.hasher.Verify(test.encoded, "a short string"); !errors.Is(err, test.want) {
+			t.Errorf("Test %d - got: %v, want: %v", i, err, test.want)
+		}
+	}
+}